@@ -1,48 +1,49 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 	"math"
-	"math/rand"
 	"sort"
-	"time"
 
 	"github.com/thanos-fil/planner-demo-go/internal/graph"
-	"github.com/thanos-fil/planner-demo-go/internal/neighbourhood"
+	"github.com/thanos-fil/planner-demo-go/internal/netgen"
 	"github.com/thanos-fil/planner-demo-go/internal/planner"
 )
 
-// PipeID is an alias for graph.ID for consistency
-type PipeID = graph.ID
-
-// ProjectMetrics contains various metrics for evaluating projects
-type ProjectMetrics struct {
-	planner.Cluster
-	TotalLength    float64
-	AvgRisk        float64
-	RiskDensity    float64 // Risk per km
-	LoFLengthRatio float64 // LoF divided by Length
-	EstimatedCost  float64 // Cost to implement project
-	BRE            float64 // Business Risk Exposure (LoF × CoF)
-	ROI            float64 // Return on Investment (BRE/Cost)
-	Priority       float64 // Overall priority score
-}
+// strategyOrder is the order strategies are planned, compared and reported in
+var strategyOrder = []string{"High LoF", "High Risk Density", "High LoF/Length"}
 
-// CostOfFailure represents the cost of failure for different pipe scenarios
-type CostOfFailure struct {
-	MinorLeak   float64 // €1,000 - €5,000
-	MajorLeak   float64 // €5,000 - €25,000
-	Burst       float64 // €25,000 - €100,000
-	ServiceDist float64 // €10,000 - €50,000 for service disruption
+// scoredProject is a candidate project with the demo's overall priority score
+type scoredProject struct {
+	planner.ProjectMetrics
+	Priority float64 // Weighted combination of ROI, risk density and average risk
 }
 
 func main() {
 	fmt.Println("=== Advanced Pipes Network Risk Assessment and ROI Planning ===")
 	fmt.Println()
 
+	seed := flag.Int64("seed", 1, "random seed for the synthetic network")
+	topology := flag.String("topology", "grid", "network shape: grid, tree, ring or towns")
+	budget := flag.Float64("budget", 2_000_000, "total budget in € for each strategy's portfolio")
+	flag.Parse()
+
 	// Create a realistic large network
-	networkSize := 2000 // Increase to 2000 pipes for more realistic scenario
-	network := createLargePipeNetwork(networkSize)
+	shape, err := netgen.ParseTopology(*topology)
+	if err != nil {
+		log.Fatal(err)
+	}
+	network := netgen.Generate(netgen.Options{
+		Seed:          *seed,
+		Pipes:         2000, // 2000 pipes for a more realistic scenario
+		Topology:      shape,
+		EdgeProb:      0.9, // Some adjacent pipes are not connected
+		CrossLinkRate: 0.1,
+		MinLength:     5, // 5-50m pipes (more realistic urban segments)
+		MaxLength:     50,
+	})
 	fmt.Printf("Created network with %d pipes\n", len(network.Nodes))
 
 	// Configuration for planning
@@ -54,12 +55,9 @@ func main() {
 	}
 
 	// Cost parameters
-	costOfFailure := CostOfFailure{
-		MinorLeak:   3000,  // €3,000 average
-		MajorLeak:   15000, // €15,000 average
-		Burst:       62500, // €62,500 average
-		ServiceDist: 30000, // €30,000 average
-	}
+	costModel := planner.DefaultCostModel()
+	cofModel := planner.DefaultCoFModel() // Minor leaks, major leaks, bursts and service disruption, per pipe
+	finance := planner.DefaultFinance()   // 4% discount rate, 2% inflation, 50 year horizon
 
 	// Analyze the network risk
 	fmt.Println("\n=== Network Risk Analysis ===")
@@ -67,105 +65,55 @@ func main() {
 
 	// Find high-risk neighborhoods using different strategies
 	fmt.Println("\n=== High-Risk Identification Strategies ===")
-	strategies := map[string][]PipeID{
-		"High LoF":          findHighLoFPipes(network),
-		"High Risk Density": findHighRiskDensityAreas(network, cfg.MaxLength),
-		"High LoF/Length":   findHighLoFLengthRatioPipes(network),
+	strategies := map[string]planner.SeedStrategy{
+		// Top 20% of pipes by risk, at most 50
+		"High LoF": planner.HighLoF{Selection: planner.Selection{Quantile: 0.2, Cap: 50}},
+		// Top 15% by risk per km within half a project length, at most 40
+		"High Risk Density": planner.RiskDensity{
+			Selection: planner.Selection{Quantile: 0.15, Cap: 40},
+			Progress: func(done, total int) {
+				if done%(max(total/10, 1)) == 0 || done == total {
+					fmt.Printf("\rComputing risk density: %d/%d pipes", done, total)
+				}
+				if done == total {
+					fmt.Println()
+				}
+			},
+		},
+		// Top 20% by LoF per metre, at most 45
+		"High LoF/Length": planner.LoFPerLength{Selection: planner.Selection{Quantile: 0.2, Cap: 45}},
+	}
+	for _, strategy := range strategyOrder {
+		fmt.Printf("%-18s seeds from planner strategy %q\n", strategy+":", strategies[strategy].Name())
 	}
 
 	// Create projects using different strategies
 	fmt.Println("\n=== Project Planning with Different Strategies ===")
-	allProjects := make(map[string][]ProjectMetrics)
+	allProjects := make(map[string][]scoredProject)
 
-	for strategy, seeds := range strategies {
+	for _, strategy := range strategyOrder {
 		fmt.Printf("\n--- Strategy: %s ---\n", strategy)
-		projects := createAdvancedProjects(network, seeds, cfg, costOfFailure)
+		candidates := createAdvancedProjects(network, strategies[strategy], cfg, costModel, cofModel, finance)
 
-		// Sort projects by priority (ROI)
-		sort.Slice(projects, func(i, j int) bool {
+		// Pick at most TargetCount non-overlapping projects that remove the most risk
+		projects := selectPortfolio(candidates, *budget, cfg.TargetCount)
+
+		// Sort projects by priority (ROI), keeping creation order for ties
+		sort.SliceStable(projects, func(i, j int) bool {
 			return projects[i].ROI > projects[j].ROI
 		})
 
-		// Keep top projects within budget
-		if len(projects) > cfg.TargetCount {
-			projects = projects[:cfg.TargetCount]
-		}
-
 		allProjects[strategy] = projects
 		displayAdvancedProjects(projects, strategy)
 	}
 
 	// Compare strategies
 	fmt.Println("\n=== Strategy Comparison ===")
-	compareStrategies(allProjects)
+	compareStrategies(allProjects, finance)
 
 	// Recommend the best strategy
 	fmt.Println("\n=== Recommendation ===")
-	recommendBestStrategy(allProjects)
-}
-
-// createLargePipeNetwork creates a realistic pipe network with varying LoF values
-func createLargePipeNetwork(numPipes int) *graph.Network {
-	net := graph.New()
-	rand.Seed(time.Now().UnixNano())
-
-	// Create pipes with realistic characteristics
-	for i := 0; i < numPipes; i++ {
-		// Simulate realistic pipe properties
-		length := 5.0 + rand.Float64()*45.0 // 5-50m pipes (more realistic urban segments)
-
-		// LoF (Likelihood of Failure) with more realistic distribution
-		var lof float64
-		age := rand.Float64()         // Simulate pipe age factor
-		material := rand.Float64()    // Simulate material quality
-		environment := rand.Float64() // Simulate environmental factors
-
-		// Combine factors for more realistic LoF
-		baseLof := 0.1 + age*0.3 + (1-material)*0.2 + environment*0.1
-		lof = math.Min(0.9, baseLof) // Cap at 0.9
-
-		net.AddNode(&graph.Node{
-			ID:     graph.ID(i),
-			Score:  lof,
-			Length: length,
-		})
-	}
-
-	// Create realistic network topology
-	gridSize := int(math.Sqrt(float64(numPipes)))
-
-	// Add grid connections with more realistic topology
-	for i := 0; i < numPipes; i++ {
-		row := i / gridSize
-		col := i % gridSize
-
-		// Connect to neighbors with some probability
-		neighbors := []int{
-			row*gridSize + col + 1, // right
-			(row+1)*gridSize + col, // bottom
-			row*gridSize + col - 1, // left
-			(row-1)*gridSize + col, // top
-		}
-
-		for _, neighbor := range neighbors {
-			if neighbor >= 0 && neighbor < numPipes && neighbor != i {
-				// Add connection with 70% probability for adjacent pipes
-				if rand.Float64() < 0.7 {
-					net.AddUndirectedEdge(graph.ID(i), graph.ID(neighbor))
-				}
-			}
-		}
-
-		// Add some random cross-connections (10% chance)
-		if rand.Float64() < 0.1 {
-			randomNeighbor := rand.Intn(numPipes)
-			if randomNeighbor != i {
-				net.AddUndirectedEdge(graph.ID(i), graph.ID(randomNeighbor))
-			}
-		}
-	}
-
-	return net
+	recommendBestStrategy(allProjects, finance)
 }
 
 // analyzeNetworkRisk provides detailed network analysis
@@ -220,191 +168,60 @@ func analyzeNetworkRisk(net *graph.Network) {
 	fmt.Printf("  Short Pipes (<15m): %d pipes (%.1f%%)\n", lengthCategories[0], float64(lengthCategories[0])/float64(len(net.Nodes))*100)
 }
 
-// findHighLoFPipes finds pipes with highest likelihood of failure
-func findHighLoFPipes(net *graph.Network) []PipeID {
-	type pipeRisk struct {
-		id   PipeID
-		risk float64
-	}
-
-	var pipes []pipeRisk
-	for id, node := range net.Nodes {
-		pipes = append(pipes, pipeRisk{id: id, risk: node.Score})
-	}
-
-	// Sort by risk (highest first)
-	sort.Slice(pipes, func(i, j int) bool {
-		return pipes[i].risk > pipes[j].risk
-	})
-
-	// Return top 20% highest risk pipes
-	numSeeds := len(pipes) / 5
-	if numSeeds > 50 {
-		numSeeds = 50 // Limit to 50 seeds
-	}
-
-	var seeds []PipeID
-	for i := 0; i < numSeeds; i++ {
-		seeds = append(seeds, pipes[i].id)
-	}
-
-	fmt.Printf("Found %d high LoF seed pipes (top %.1f%% by risk)\n", len(seeds), float64(numSeeds)/float64(len(pipes))*100)
-	return seeds
-}
-
-// findHighRiskDensityAreas finds areas with high concentration of risky pipes
-func findHighRiskDensityAreas(net *graph.Network, maxLength float64) []PipeID {
-	type areaRisk struct {
-		id          PipeID
-		riskDensity float64
+// createAdvancedProjects plans candidate projects from the strategy's seeds
+// and adds detailed metrics. Candidates may share pipes; selectPortfolio
+// picks among them.
+func createAdvancedProjects(net *graph.Network, strategy planner.SeedStrategy, cfg planner.UserCfg, costs planner.CostModel, cof planner.CoFModel, fin planner.Finance) []scoredProject {
+	cfg.SeedStrategy = strategy
+	cfg.AllowOverlap = true
+	cfg.TargetCount *= 2 // Generate more candidates than needed
+	clusters, err := planner.CreateCandidateClusters(net, cfg)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	var areas []areaRisk
+	projects := make([]scoredProject, len(clusters))
+	for i, cluster := range clusters {
+		// Cost from per-metre rates, surcharges and mobilisation, BRE from
+		// LoF × CoF per pipe, and NPV, BCR, IRR and payback over the horizon
+		metrics := planner.NewProjectMetrics(net, cluster, costs, cof, fin)
 
-	// For each pipe, calculate risk density in its neighborhood
-	for id := range net.Nodes {
-		neighbors := neighbourhood.Neighbourhood(net, id, maxLength/2) // Use smaller radius for density calculation
+		// Calculate priority score (weighted combination of factors); the
+		// LoF/length ratio of a project is its risk density
+		priority := metrics.ROI*0.4 + metrics.RiskDensity*0.4 + metrics.AvgRisk*0.2
 
-		var totalRisk, totalLength float64
-		for neighborID := range neighbors {
-			node := net.Nodes[graph.ID(neighborID)]
-			totalRisk += node.Score
-			totalLength += node.Length
-		}
-
-		if totalLength > 0 {
-			riskDensity := totalRisk / (totalLength / 1000) // Risk per km
-			areas = append(areas, areaRisk{id: id, riskDensity: riskDensity})
-		}
-	}
-
-	// Sort by risk density
-	sort.Slice(areas, func(i, j int) bool {
-		return areas[i].riskDensity > areas[j].riskDensity
-	})
-
-	// Return top 15% by risk density
-	numSeeds := len(areas) * 15 / 100
-	if numSeeds > 40 {
-		numSeeds = 40
+		projects[i] = scoredProject{ProjectMetrics: metrics, Priority: priority}
 	}
-
-	var seeds []PipeID
-	for i := 0; i < numSeeds; i++ {
-		seeds = append(seeds, areas[i].id)
-	}
-
-	fmt.Printf("Found %d high risk density seed areas\n", len(seeds))
-	return seeds
+	return projects
 }
 
-// findHighLoFLengthRatioPipes finds pipes with the best LoF/Length ratio
-func findHighLoFLengthRatioPipes(net *graph.Network) []PipeID {
-	type pipeEfficiency struct {
-		id    PipeID
-		ratio float64
-	}
-
-	var pipes []pipeEfficiency
-	for id, node := range net.Nodes {
-		ratio := node.Score / (node.Length / 1000) // LoF per km
-		pipes = append(pipes, pipeEfficiency{id: id, ratio: ratio})
+// selectPortfolio picks at most maxProjects candidates that share no pipes,
+// fit the budget and remove the most risk exposure.
+func selectPortfolio(candidates []scoredProject, budget float64, maxProjects int) []scoredProject {
+	cands := make([]planner.Candidate, len(candidates))
+	byID := make(map[int]scoredProject, len(candidates))
+	for i, p := range candidates {
+		cands[i] = p.Candidate()
+		byID[p.ID] = p
 	}
 
-	// Sort by ratio (highest first)
-	sort.Slice(pipes, func(i, j int) bool {
-		return pipes[i].ratio > pipes[j].ratio
-	})
-
-	// Return top 20%
-	numSeeds := len(pipes) / 5
-	if numSeeds > 45 {
-		numSeeds = 45
+	portfolio, err := planner.SelectPortfolio(cands, budget, planner.PortfolioOptions{MaxProjects: maxProjects})
+	if err != nil {
+		log.Fatal(err)
 	}
+	fmt.Printf("Portfolio: %d of %d candidates, cost €%.0f of €%.0f, BRE €%.0f (%s, gap %.1f%%)\n",
+		len(portfolio.Projects), len(candidates), portfolio.Cost, budget, portfolio.Benefit,
+		portfolio.Method, portfolio.Gap*100)
 
-	var seeds []PipeID
-	for i := 0; i < numSeeds; i++ {
-		seeds = append(seeds, pipes[i].id)
-	}
-
-	fmt.Printf("Found %d high LoF/Length ratio seed pipes\n", len(seeds))
-	return seeds
-}
-
-// createAdvancedProjects creates projects with detailed metrics
-func createAdvancedProjects(net *graph.Network, seeds []PipeID, cfg planner.UserCfg, cof CostOfFailure) []ProjectMetrics {
-	var projects []ProjectMetrics
-	usedPipes := make(map[PipeID]bool)
-
-	for i, seed := range seeds {
-		if usedPipes[seed] {
-			continue
-		}
-
-		neighbors := neighbourhood.Neighbourhood(net, seed, cfg.MaxLength)
-
-		var clusterNodes []graph.ID
-		var totalRisk, totalLength float64
-
-		for pipeID := range neighbors {
-			if !usedPipes[pipeID] {
-				node := net.Nodes[graph.ID(pipeID)]
-				clusterNodes = append(clusterNodes, graph.ID(pipeID))
-				totalRisk += node.Score
-				totalLength += node.Length
-				usedPipes[pipeID] = true
-			}
-		}
-
-		if len(clusterNodes) > 0 {
-			// Calculate advanced metrics
-			avgRisk := totalRisk / float64(len(clusterNodes))
-			riskDensity := totalRisk / (totalLength / 1000)
-			lofLengthRatio := totalRisk / (totalLength / 1000)
-
-			// Estimate project cost (€500 per meter + fixed costs)
-			estimatedCost := totalLength*500 + 10000 // €500/m + €10k fixed
-
-			// Calculate Business Risk Exposure (LoF × Cost of Failure)
-			// Assume different failure types based on LoF level
-			avgCoF := (cof.MinorLeak + cof.MajorLeak + cof.Burst + cof.ServiceDist) / 4
-			bre := totalRisk * avgCoF
-
-			// Calculate ROI (potential savings / investment cost)
-			roi := bre / estimatedCost
-
-			// Calculate priority score (weighted combination of factors)
-			priority := roi*0.4 + riskDensity*0.3 + avgRisk*0.2 + lofLengthRatio*0.1
-
-			project := ProjectMetrics{
-				Cluster: planner.Cluster{
-					ID:    i + 1,
-					Nodes: clusterNodes,
-					Score: totalRisk,
-				},
-				TotalLength:    totalLength,
-				AvgRisk:        avgRisk,
-				RiskDensity:    riskDensity,
-				LoFLengthRatio: lofLengthRatio,
-				EstimatedCost:  estimatedCost,
-				BRE:            bre,
-				ROI:            roi,
-				Priority:       priority,
-			}
-
-			projects = append(projects, project)
-		}
-
-		if len(projects) >= cfg.TargetCount*2 { // Generate more candidates than needed
-			break
-		}
+	projects := make([]scoredProject, len(portfolio.Projects))
+	for i, c := range portfolio.Projects {
+		projects[i] = byID[c.ID]
 	}
-
 	return projects
 }
 
 // displayAdvancedProjects shows projects with comprehensive metrics
-func displayAdvancedProjects(projects []ProjectMetrics, strategy string) {
+func displayAdvancedProjects(projects []scoredProject, strategy string) {
 	fmt.Printf("Top %d Projects for %s Strategy:\n", len(projects), strategy)
 	fmt.Println("ID | Pipes | Length(m) | AvgRisk | RiskDens | Cost(€) | BRE(€) | ROI | Priority")
 	fmt.Println("----|-------|-----------|---------|----------|---------|---------|-----|--------")
@@ -416,10 +233,10 @@ func displayAdvancedProjects(projects []ProjectMetrics, strategy string) {
 		fmt.Printf("%2d | %5d | %7.0f | %7.3f | %8.1f | %7.0f | %7.0f | %.2f | %8.2f\n",
 			project.ID,
 			len(project.Nodes),
-			project.TotalLength,
+			project.Length,
 			project.AvgRisk,
 			project.RiskDensity,
-			project.EstimatedCost,
+			project.Cost,
 			project.BRE,
 			project.ROI,
 			project.Priority)
@@ -428,38 +245,39 @@ func displayAdvancedProjects(projects []ProjectMetrics, strategy string) {
 }
 
 // compareStrategies compares the effectiveness of different strategies
-func compareStrategies(allProjects map[string][]ProjectMetrics) {
+func compareStrategies(allProjects map[string][]scoredProject, fin planner.Finance) {
 	fmt.Printf("Strategy Comparison Summary:\n")
-	fmt.Printf("%-20s | %s | %s | %s | %s\n", "Strategy", "Avg ROI", "Avg Risk", "Total Cost(€)", "Total BRE(€)")
-	fmt.Printf("---------------------|---------|----------|-------------|-------------\n")
+	fmt.Printf("%-20s | %s | %s | %s | %s | %s | %s\n", "Strategy", "Avg ROI", "Avg Risk", "Total Cost(€)", "Total BRE(€)", "NPV(€)", "BCR")
+	fmt.Printf("---------------------|---------|----------|-------------|-------------|-------------|-----\n")
 
-	for strategy, projects := range allProjects {
+	for _, strategy := range strategyOrder {
+		projects := allProjects[strategy]
 		if len(projects) == 0 {
 			continue
 		}
 
-		var totalROI, totalRisk, totalCost, totalBRE float64
+		var totalROI, totalRisk float64
 		for _, project := range projects {
 			totalROI += project.ROI
 			totalRisk += project.AvgRisk
-			totalCost += project.EstimatedCost
-			totalBRE += project.BRE
 		}
 
 		avgROI := totalROI / float64(len(projects))
 		avgRisk := totalRisk / float64(len(projects))
+		portfolio := combine(projects, fin)
 
-		fmt.Printf("%-20s | %7.2f | %8.3f | %11.0f | %11.0f\n",
-			strategy, avgROI, avgRisk, totalCost, totalBRE)
+		fmt.Printf("%-20s | %7.2f | %8.3f | %11.0f | %11.0f | %11.0f | %.2f\n",
+			strategy, avgROI, avgRisk, portfolio.Cost, portfolio.BRE, portfolio.NPV, portfolio.BCR)
 	}
 }
 
 // recommendBestStrategy provides a final recommendation
-func recommendBestStrategy(allProjects map[string][]ProjectMetrics) {
+func recommendBestStrategy(allProjects map[string][]scoredProject, fin planner.Finance) {
 	bestStrategy := ""
 	bestScore := 0.0
 
-	for strategy, projects := range allProjects {
+	for _, strategy := range strategyOrder {
+		projects := allProjects[strategy]
 		if len(projects) == 0 {
 			continue
 		}
@@ -486,16 +304,28 @@ func recommendBestStrategy(allProjects map[string][]ProjectMetrics) {
 		fmt.Printf("This strategy offers the best combination of ROI and overall priority.\n")
 
 		projects := allProjects[bestStrategy]
-		var totalCost, totalBRE float64
-		for _, project := range projects {
-			totalCost += project.EstimatedCost
-			totalBRE += project.BRE
+		portfolio := combine(projects, fin)
+
+		fmt.Printf("\nExpected Outcomes (%d year horizon, %.0f%% discount rate, %.0f%% inflation):\n",
+			fin.Horizon, fin.DiscountRate*100, fin.InflationRate*100)
+		fmt.Printf("  Total Investment: €%.0f (€%.0f/year over %d years)\n", portfolio.Cost, portfolio.AnnualCapex, fin.AssetLife)
+		fmt.Printf("  Total Risk Exposure Addressed: €%.0f/year\n", portfolio.BRE)
+		fmt.Printf("  Present Value of Avoided Failures: €%.0f\n", portfolio.PVBenefit)
+		fmt.Printf("  NPV: €%.0f, Benefit/Cost Ratio: %.2f, IRR: %.1f%%\n", portfolio.NPV, portfolio.BCR, portfolio.IRR*100)
+		if math.IsInf(portfolio.Payback, 1) {
+			fmt.Printf("  Discounted Payback Period: beyond %d years\n", fin.Horizon)
+		} else {
+			fmt.Printf("  Discounted Payback Period: %.1f years\n", portfolio.Payback)
 		}
-
-		fmt.Printf("\nExpected Outcomes:\n")
-		fmt.Printf("  Total Investment: €%.0f\n", totalCost)
-		fmt.Printf("  Total Risk Exposure Addressed: €%.0f\n", totalBRE)
-		fmt.Printf("  Expected Payback Period: %.1f years\n", totalCost/totalBRE*10) // Rough estimate
 		fmt.Printf("  Number of Projects: %d\n", len(projects))
 	}
 }
+
+// combine appraises the projects of a strategy as one portfolio.
+func combine(projects []scoredProject, fin planner.Finance) planner.ProjectMetrics {
+	metrics := make([]planner.ProjectMetrics, len(projects))
+	for i, project := range projects {
+		metrics[i] = project.ProjectMetrics
+	}
+	return planner.CombineMetrics(metrics, fin)
+}
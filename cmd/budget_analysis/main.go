@@ -5,6 +5,7 @@ import (
 
 	"github.com/thanos-fil/planner-demo-go/internal/graph"
 	"github.com/thanos-fil/planner-demo-go/internal/neighbourhood"
+	"github.com/thanos-fil/planner-demo-go/internal/planner"
 )
 
 func main() {
@@ -21,12 +22,24 @@ func main() {
 
 	fmt.Printf("\n=== Budget Scenario Analysis (Start Node: %d) ===\n", startNode)
 
+	// One search with the largest budget answers every smaller one
+	sweep := neighbourhood.NewSweep(net, startNode, budgets[len(budgets)-1])
 	for _, budget := range budgets {
 		fmt.Printf("\n--- Budget: %.1f ---\n", budget)
-		result := neighbourhood.Neighbourhood(net, startNode, budget)
+		result := make(map[neighbourhood.PipeID]struct{})
+		for _, id := range sweep.Prefix(budget) {
+			result[id] = struct{}{}
+		}
 		analyzeScenario(net, result, budget, startNode)
 	}
 
+	// The budget at which every pipe joins gives the full curve
+	fmt.Printf("\n=== Budget Sweep Curve (Start Node: %d) ===\n", startNode)
+	fmt.Printf("  %-8s %-6s %-7s %-8s %s\n", "Budget", "Pipe", "Pipes", "Length", "Risk")
+	for i, id := range sweep.Pipes {
+		fmt.Printf("  %-8.1f %-6d %-7d %-8.1f %.2f\n", sweep.Dist[i], id, i+1, sweep.TotalLength[i], sweep.TotalRisk[i])
+	}
+
 	// Show the impact of different starting nodes
 	fmt.Printf("\n=== Starting Node Impact Analysis (Budget: 8.0) ===\n")
 	budget := 8.0
@@ -83,7 +96,8 @@ func analyzeScenario(net *graph.Network, result map[neighbourhood.PipeID]struct{
 	var totalRisk, totalLength float64
 	var visitedNodes []int
 
-	for nodeID := range result {
+	pipes := neighbourhood.SortedIDs(result)
+	for _, nodeID := range pipes {
 		node := net.Nodes[graph.ID(nodeID)]
 		totalRisk += node.Score
 		totalLength += node.Length
@@ -91,9 +105,8 @@ func analyzeScenario(net *graph.Network, result map[neighbourhood.PipeID]struct{
 	}
 
 	// Calculate project metrics
-	estimatedCost := totalLength*500 + 10000
-	avgCoF := 27500.0
-	bre := totalRisk * avgCoF
+	estimatedCost := planner.DefaultCostModel().Cost(net, pipes)
+	bre := planner.BRE(net, pipes, planner.DefaultCoFModel())
 	roi := bre / estimatedCost
 
 	fmt.Printf("Results:\n")
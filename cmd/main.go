@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/thanos-fil/planner-demo-go/internal/planner"
 )
@@ -9,8 +10,20 @@ import (
 func main() {
 	net := planner.MockExampleNetwork()
 
-	cfg := planner.UserCfg{}
+	cfg := planner.UserCfg{
+		TargetCount:         5,
+		MaxLength:           3.0,
+		OvershootFactor:     1.2,
+		LongestPathFraction: 0.8,
+	}
 
-	clusters := planner.CreateCandidateClusters(net, cfg)
+	clusters, err := planner.CreateCandidateClusters(net, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 	fmt.Printf("Created %d clusters\n", len(clusters))
+	for _, c := range clusters {
+		fmt.Printf("  Cluster %d: seed=%d pipes=%v score=%.2f length=%.1f\n",
+			c.ID, c.Seed, c.Nodes, c.Score, c.Length)
+	}
 }
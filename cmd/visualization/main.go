@@ -6,6 +6,7 @@ import (
 
 	"github.com/thanos-fil/planner-demo-go/internal/graph"
 	"github.com/thanos-fil/planner-demo-go/internal/neighbourhood"
+	"github.com/thanos-fil/planner-demo-go/internal/planner"
 )
 
 // Step represents one step in the algorithm execution
@@ -55,9 +56,11 @@ func main() {
 	// Show final result
 	fmt.Printf("\n=== Final Result ===\n")
 	fmt.Printf("Reachable nodes within budget %.1f:\n", maxLength)
-	for nodeID := range result {
+	tree := neighbourhood.Search(net, startNode, maxLength)
+	for _, nodeID := range tree.Sorted() {
 		node := net.Nodes[graph.ID(nodeID)]
-		fmt.Printf("  Node %d: LoF=%.2f, Length=%.1f\n", nodeID, node.Score, node.Length)
+		fmt.Printf("  Node %d: LoF=%.2f, Length=%.1f, cumulative=%.1f, path=%v\n",
+			nodeID, node.Score, node.Length, tree.Dist[nodeID], tree.Path(nodeID))
 	}
 
 	// Create ASCII graph visualization
@@ -127,127 +130,79 @@ func displayNetworkStructure(net *graph.Network) {
 	}
 }
 
-// runNeighbourhoodWithSteps runs the algorithm and captures each step
+// runNeighbourhoodWithSteps runs the real search with a tracer attached and
+// turns every event into a step, rebuilding the queue from the events
 func runNeighbourhoodWithSteps(net *graph.Network, root graph.ID, maxLen float64) (map[neighbourhood.PipeID]struct{}, []Step) {
 	var steps []Step
-	stepNum := 0
-
-	// Initialize tracking variables
-	inQueue := map[neighbourhood.PipeID]float64{root: net.Nodes[root].Length}
-	visited := map[neighbourhood.PipeID]struct{}{}
-	visitedWithDistance := map[graph.ID]float64{}
-
-	// We'll simulate the priority queue for demonstration
-	type pqItem struct {
-		id     neighbourhood.PipeID
-		cumLen float64
+	var queue []QueueItem
+	visited := map[graph.ID]float64{}
+	inQueue := map[neighbourhood.PipeID]float64{}
+
+	dequeue := func(id graph.ID, cumLen float64) {
+		for i, item := range queue {
+			if item.ID == id && item.CumLen == cumLen {
+				queue = append(queue[:i], queue[i+1:]...)
+				return
+			}
+		}
 	}
 
-	var pq []pqItem
-	pq = append(pq, pqItem{id: root, cumLen: inQueue[root]})
-
-	// Initial state
-	stepNum++
-	steps = append(steps, Step{
-		StepNum:      stepNum,
-		Action:       fmt.Sprintf("Initialize: Add root node %d with cumLen=%.1f to queue", root, inQueue[root]),
-		CurrentNode:  root,
-		CumLen:       inQueue[root],
-		QueueState:   []QueueItem{{ID: root, CumLen: inQueue[root]}},
-		VisitedNodes: make(map[graph.ID]float64),
-		InQueue:      copyPipeIDMap(inQueue),
-	})
-
-	for len(pq) > 0 {
-		// Sort pq to simulate priority queue (smallest cumLen first)
-		sort.Slice(pq, func(i, j int) bool {
-			return pq[i].cumLen < pq[j].cumLen
-		})
-
-		// Pop the item with smallest cumulative length
-		current := pq[0]
-		pq = pq[1:]
-
-		stepNum++
-		var queueState []QueueItem
-		for _, item := range pq {
-			queueState = append(queueState, QueueItem{ID: item.id, CumLen: item.cumLen})
+	tracer := neighbourhood.TracerFunc(func(e neighbourhood.Event) {
+		var action string
+		current := e.From
+		// Steps report the length of the path the event is about; events on
+		// a neighbour keep the length of the pipe being expanded
+		var cumLen float64
+		if len(steps) > 0 {
+			cumLen = steps[len(steps)-1].CumLen
 		}
-
-		if current.cumLen > maxLen {
-			steps = append(steps, Step{
-				StepNum:      stepNum,
-				Action:       fmt.Sprintf("Pop node %d (cumLen=%.1f) - EXCEEDS BUDGET, skip", current.id, current.cumLen),
-				CurrentNode:  current.id,
-				CumLen:       current.cumLen,
-				QueueState:   queueState,
-				VisitedNodes: copyGraphIDMap(visitedWithDistance),
-				InQueue:      copyPipeIDMap(inQueue),
-			})
-			continue
+		switch e.Kind {
+		case neighbourhood.Push, neighbourhood.Relax:
+			queue = append(queue, QueueItem{ID: e.Pipe, CumLen: e.CumLen})
+			sort.SliceStable(queue, func(i, j int) bool { return queue[i].CumLen < queue[j].CumLen })
+			inQueue[e.Pipe] = e.CumLen
+			switch {
+			case e.Pipe == e.From:
+				cumLen = e.CumLen
+				action = fmt.Sprintf("Initialize: Add root node %d with cumLen=%.1f to queue", e.Pipe, e.CumLen)
+			case e.Kind == neighbourhood.Relax:
+				action = fmt.Sprintf("  → Explore neighbor %d: newCumLen=%.1f (improved from %.1f) → Add to queue", e.Pipe, e.CumLen, e.Prev)
+			default:
+				action = fmt.Sprintf("  → Explore neighbor %d: newCumLen=%.1f (first time) → Add to queue", e.Pipe, e.CumLen)
+			}
+		case neighbourhood.Pop:
+			dequeue(e.Pipe, e.CumLen)
+			visited[e.Pipe] = e.CumLen
+			current, cumLen = e.Pipe, e.CumLen
+			action = fmt.Sprintf("Pop and visit node %d (cumLen=%.1f)", e.Pipe, e.CumLen)
+		case neighbourhood.Prune:
+			dequeue(e.Pipe, e.CumLen)
+			current, cumLen = e.Pipe, e.CumLen
+			action = fmt.Sprintf("Pop node %d (cumLen=%.1f) - EXCEEDS BUDGET, skip", e.Pipe, e.CumLen)
+		case neighbourhood.Skip:
+			if e.Dequeued {
+				dequeue(e.Pipe, e.CumLen)
+				current, cumLen = e.Pipe, e.CumLen
+				action = fmt.Sprintf("Pop node %d (cumLen=%.1f) - already visited at %.1f, skip", e.Pipe, e.CumLen, e.Prev)
+			} else {
+				action = fmt.Sprintf("  → Explore neighbor %d: newCumLen=%.1f ≥ existing %.1f → Skip", e.Pipe, e.CumLen, e.Prev)
+			}
 		}
 
-		// Visit the node
-		visited[current.id] = struct{}{}
-		visitedWithDistance[graph.ID(current.id)] = current.cumLen
-
 		steps = append(steps, Step{
-			StepNum:      stepNum,
-			Action:       fmt.Sprintf("Pop and visit node %d (cumLen=%.1f)", current.id, current.cumLen),
-			CurrentNode:  current.id,
-			CumLen:       current.cumLen,
-			QueueState:   queueState,
-			VisitedNodes: copyGraphIDMap(visitedWithDistance),
+			StepNum:      len(steps) + 1,
+			Action:       action,
+			CurrentNode:  current,
+			CumLen:       cumLen,
+			QueueState:   append([]QueueItem(nil), queue...),
+			VisitedNodes: copyGraphIDMap(visited),
 			InQueue:      copyPipeIDMap(inQueue),
 		})
+	})
 
-		// Explore neighbors
-		for _, nbr := range net.Edges[current.id] {
-			nextLen := current.cumLen + net.Nodes[nbr].Length
-
-			stepNum++
-			if prev, ok := inQueue[neighbourhood.PipeID(nbr)]; !ok || nextLen < prev {
-				inQueue[neighbourhood.PipeID(nbr)] = nextLen
-				pq = append(pq, pqItem{id: neighbourhood.PipeID(nbr), cumLen: nextLen})
-
-				// Update queue state for display
-				queueState = nil
-				for _, item := range pq {
-					queueState = append(queueState, QueueItem{ID: item.id, CumLen: item.cumLen})
-				}
-
-				action := fmt.Sprintf("  → Explore neighbor %d: newCumLen=%.1f", nbr, nextLen)
-				if ok {
-					action += fmt.Sprintf(" (improved from %.1f)", prev)
-				} else {
-					action += " (first time)"
-				}
-				action += " → Add to queue"
-
-				steps = append(steps, Step{
-					StepNum:      stepNum,
-					Action:       action,
-					CurrentNode:  current.id,
-					CumLen:       current.cumLen,
-					QueueState:   queueState,
-					VisitedNodes: copyGraphIDMap(visitedWithDistance),
-					InQueue:      copyPipeIDMap(inQueue),
-				})
-			} else {
-				steps = append(steps, Step{
-					StepNum:      stepNum,
-					Action:       fmt.Sprintf("  → Explore neighbor %d: newCumLen=%.1f ≥ existing %.1f → Skip", nbr, nextLen, prev),
-					CurrentNode:  current.id,
-					CumLen:       current.cumLen,
-					QueueState:   queueState,
-					VisitedNodes: copyGraphIDMap(visitedWithDistance),
-					InQueue:      copyPipeIDMap(inQueue),
-				})
-			}
-		}
-	}
-
-	return visited, steps
+	seeds := []neighbourhood.Seed{{Root: root, Budget: maxLen}}
+	result := neighbourhood.SearchWith(net, seeds, neighbourhood.Options{Tracer: tracer})
+	return result.Pipes(), steps
 }
 
 // Helper functions for copying maps
@@ -409,7 +364,9 @@ func analyzeProject(net *graph.Network, result map[neighbourhood.PipeID]struct{}
 	}
 	sort.Ints(resultNodes)
 
+	var pipes []graph.ID
 	for _, id := range resultNodes {
+		pipes = append(pipes, graph.ID(id))
 		node := net.Nodes[graph.ID(id)]
 		totalRisk += node.Score
 		totalLength += node.Length
@@ -425,13 +382,12 @@ func analyzeProject(net *graph.Network, result map[neighbourhood.PipeID]struct{}
 	fmt.Printf("  Risk Density: %.2f LoF/km\n", totalRisk/(totalLength/1000))
 
 	// Cost analysis
-	estimatedCost := totalLength*500 + 10000
-	avgCoF := 27500.0 // Average cost of failure
-	bre := totalRisk * avgCoF
+	estimatedCost := planner.DefaultCostModel().Cost(net, pipes)
+	bre := planner.BRE(net, pipes, planner.DefaultCoFModel()) // LoF × CoF per pipe
 	roi := bre / estimatedCost
 
 	fmt.Printf("\nFinancial Analysis:\n")
-	fmt.Printf("  Estimated Cost: €%.0f (€500/m + €10k fixed)\n", estimatedCost)
+	fmt.Printf("  Estimated Cost: €%.0f (default cost model)\n", estimatedCost)
 	fmt.Printf("  Business Risk Exposure: €%.0f\n", bre)
 	fmt.Printf("  ROI: %.2f\n", roi)
 }
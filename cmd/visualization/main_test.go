@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunNeighbourhoodWithStepsCumLen(t *testing.T) {
+	// Pipe 6 is reached through 0, 2 and 5: 2 + 3 + 2.5 + 1.8 = 9.3 > 8
+	_, steps := runNeighbourhoodWithSteps(createSmallDemoNetwork(), 0, 8)
+
+	var pruned *Step
+	for i, step := range steps {
+		if strings.Contains(step.Action, "EXCEEDS BUDGET") {
+			pruned = &steps[i]
+		}
+		if strings.Contains(step.Action, "Explore neighbor") && i > 0 && step.CumLen != steps[i-1].CumLen {
+			t.Errorf("step %d: expected the expanded pipe's cumLen %v, got %v", step.StepNum, steps[i-1].CumLen, step.CumLen)
+		}
+	}
+	if pruned == nil {
+		t.Fatal("expected a prune step")
+	}
+	if pruned.CurrentNode != 6 || pruned.CumLen < 9.3-1e-9 || pruned.CumLen > 9.3+1e-9 {
+		t.Errorf("expected the prune step to report pipe 6 at 9.3, got pipe %d at %v", pruned.CurrentNode, pruned.CumLen)
+	}
+}
@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Names of the typed Node attributes as used by Attr, SetAttr and the
+// importers.
+const (
+	AttrMaterial    = "material"
+	AttrDiameter    = "diameter"
+	AttrInstallYear = "install_year"
+	AttrZone        = "zone"
+	AttrStartX      = "start_x"
+	AttrStartY      = "start_y"
+	AttrEndX        = "end_x"
+	AttrEndY        = "end_y"
+)
+
+// typedAttrs lists the typed attributes in their canonical column order.
+var typedAttrs = []string{
+	AttrMaterial, AttrDiameter, AttrInstallYear, AttrZone,
+	AttrStartX, AttrStartY, AttrEndX, AttrEndY,
+}
+
+// IsTypedAttr reports whether key names one of the typed Node fields.
+func IsTypedAttr(key string) bool {
+	for _, k := range typedAttrs {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Attr returns the named attribute as a string. Typed fields are looked up
+// by their Attr* name, anything else comes from Attrs. The second result is
+// false when the attribute is unset.
+func (n *Node) Attr(key string) (string, bool) {
+	switch key {
+	case AttrMaterial:
+		return n.Material, n.Material != ""
+	case AttrDiameter:
+		return formatFloat(n.Diameter), n.Diameter != 0
+	case AttrInstallYear:
+		return strconv.Itoa(n.InstallYear), n.InstallYear != 0
+	case AttrZone:
+		return n.Zone, n.Zone != ""
+	case AttrStartX, AttrStartY:
+		return pointCoord(n.Start, key == AttrStartX)
+	case AttrEndX, AttrEndY:
+		return pointCoord(n.End, key == AttrEndX)
+	}
+	v, ok := n.Attrs[key]
+	return v, ok
+}
+
+// SetAttr sets the named attribute from its string form, parsing the value
+// for typed fields. Unknown keys are stored in Attrs.
+func (n *Node) SetAttr(key, value string) error {
+	switch key {
+	case AttrMaterial:
+		n.Material = value
+	case AttrZone:
+		n.Zone = value
+	case AttrDiameter:
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", key, value)
+		}
+		n.Diameter = v
+	case AttrInstallYear:
+		v, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", key, value)
+		}
+		n.InstallYear = v
+	case AttrStartX, AttrStartY, AttrEndX, AttrEndY:
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", key, value)
+		}
+		p := &n.Start
+		if key == AttrEndX || key == AttrEndY {
+			p = &n.End
+		}
+		if *p == nil {
+			*p = &Point{}
+		}
+		if key == AttrStartX || key == AttrEndX {
+			(*p).X = v
+		} else {
+			(*p).Y = v
+		}
+	default:
+		if n.Attrs == nil {
+			n.Attrs = make(map[string]string)
+		}
+		n.Attrs[key] = value
+	}
+	return nil
+}
+
+// Age returns the age of the pipe in the given year. The second result is
+// false when the install year is unknown.
+func (n *Node) Age(year int) (int, bool) {
+	if n.InstallYear == 0 {
+		return 0, false
+	}
+	return year - n.InstallYear, true
+}
+
+func pointCoord(p *Point, x bool) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	if x {
+		return formatFloat(p.X), true
+	}
+	return formatFloat(p.Y), true
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
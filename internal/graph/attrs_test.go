@@ -0,0 +1,49 @@
+package graph
+
+import "testing"
+
+func TestNodeAttr(t *testing.T) {
+	n := &Node{ID: 1}
+	set := map[string]string{
+		AttrMaterial:    "PVC",
+		AttrDiameter:    "110",
+		AttrInstallYear: "1975",
+		AttrZone:        "north",
+		AttrStartX:      "4.35",
+		AttrEndY:        "50.85",
+		"owner":         "municipality",
+	}
+	for k, v := range set {
+		if err := n.SetAttr(k, v); err != nil {
+			t.Fatalf("SetAttr(%q, %q): %v", k, v, err)
+		}
+	}
+
+	if n.Material != "PVC" || n.Diameter != 110 || n.InstallYear != 1975 || n.Zone != "north" {
+		t.Errorf("typed fields not set: %+v", n)
+	}
+	if n.Start == nil || n.Start.X != 4.35 || n.End == nil || n.End.Y != 50.85 {
+		t.Errorf("coordinates not set: start=%v end=%v", n.Start, n.End)
+	}
+	if len(n.Attrs) != 1 || n.Attrs["owner"] != "municipality" {
+		t.Errorf("expected only the custom field in Attrs, got %v", n.Attrs)
+	}
+	for k, v := range set {
+		if got, ok := n.Attr(k); !ok || got != v {
+			t.Errorf("Attr(%q): expected %q, got %q (ok=%v)", k, v, got, ok)
+		}
+	}
+	if _, ok := n.Attr(AttrStartY); !ok {
+		t.Errorf("expected start_y to be set once start_x is")
+	}
+	if _, ok := (&Node{}).Attr(AttrDiameter); ok {
+		t.Errorf("expected zero diameter to be reported as unset")
+	}
+
+	if age, ok := n.Age(2025); !ok || age != 50 {
+		t.Errorf("expected age 50, got %d (ok=%v)", age, ok)
+	}
+	if err := n.SetAttr(AttrDiameter, "DN110"); err == nil {
+		t.Errorf("expected an error for a non-numeric diameter")
+	}
+}
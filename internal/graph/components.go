@@ -0,0 +1,88 @@
+package graph
+
+import "sort"
+
+// Component is a maximal set of pipes connected to each other.
+type Component struct {
+	Label       int     // Position in the slice returned by Components
+	Nodes       []ID    // Pipes in the component, in ascending order
+	TotalLength float64 // Sum of the pipe lengths
+	TotalRisk   float64 // Sum of the pipe scores
+}
+
+// Components labels the connected components ("islands") of the network.
+// Components are ordered by pipe count, largest first, with ties broken by
+// their lowest pipe ID, and labelled 0, 1, ... in that order. Edges to pipes
+// that do not exist are ignored.
+func (n *Network) Components() []Component {
+	seen := make(map[ID]bool, len(n.Nodes))
+	var comps []Component
+
+	for _, start := range sortedIDs(n.Nodes) {
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+
+		var comp Component
+		stack := []ID{start}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			node := n.Nodes[id]
+			comp.Nodes = append(comp.Nodes, id)
+			comp.TotalLength += node.Length
+			comp.TotalRisk += node.Score
+
+			for _, nbr := range n.Edges[id] {
+				if _, ok := n.Nodes[nbr]; ok && !seen[nbr] {
+					seen[nbr] = true
+					stack = append(stack, nbr)
+				}
+			}
+		}
+		sort.Slice(comp.Nodes, func(i, j int) bool { return comp.Nodes[i] < comp.Nodes[j] })
+		comps = append(comps, comp)
+	}
+
+	sort.SliceStable(comps, func(i, j int) bool {
+		return len(comps[i].Nodes) > len(comps[j].Nodes)
+	})
+	for i := range comps {
+		comps[i].Label = i
+	}
+	return comps
+}
+
+// ComponentLabels maps every pipe to the label of its component as numbered
+// by Components.
+func (n *Network) ComponentLabels() map[ID]int {
+	labels := make(map[ID]int, len(n.Nodes))
+	for _, comp := range n.Components() {
+		for _, id := range comp.Nodes {
+			labels[id] = comp.Label
+		}
+	}
+	return labels
+}
+
+// Subnetwork returns a Network holding only the given pipes and the edges
+// between them, e.g. to plan on a single component. Node values are shared
+// with n, not copied. IDs that do not exist in n are ignored.
+func (n *Network) Subnetwork(ids []ID) *Network {
+	sub := New()
+	for _, id := range ids {
+		if node, ok := n.Nodes[id]; ok {
+			sub.AddNode(node)
+		}
+	}
+	for id := range sub.Nodes {
+		for _, nbr := range n.Edges[id] {
+			if _, ok := sub.Nodes[nbr]; ok {
+				sub.Edges[id] = append(sub.Edges[id], nbr)
+			}
+		}
+	}
+	return sub
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComponents(t *testing.T) {
+	net := New()
+	for i := 1; i <= 7; i++ {
+		net.AddNode(&Node{ID: ID(i), Score: 0.1 * float64(i), Length: float64(i)})
+	}
+	// Islands: {1, 2}, {3, 4, 5}, {6}, {7}
+	net.AddUndirectedEdge(1, 2)
+	net.AddUndirectedEdge(3, 4)
+	net.AddUndirectedEdge(4, 5)
+	net.AddUndirectedEdge(5, 3)
+	net.AddUndirectedEdge(6, 99) // dangling, ignored
+
+	comps := net.Components()
+
+	expected := [][]ID{{3, 4, 5}, {1, 2}, {6}, {7}}
+	if len(comps) != len(expected) {
+		t.Fatalf("expected %d components, got %d: %v", len(expected), len(comps), comps)
+	}
+	for i, c := range comps {
+		if c.Label != i {
+			t.Errorf("component %d: expected label %d, got %d", i, i, c.Label)
+		}
+		if !reflect.DeepEqual(c.Nodes, expected[i]) {
+			t.Errorf("component %d: expected %v, got %v", i, expected[i], c.Nodes)
+		}
+	}
+
+	if comps[0].TotalLength != 12 {
+		t.Errorf("expected total length 12, got %v", comps[0].TotalLength)
+	}
+	if got := comps[1].TotalRisk; got < 0.3-1e-9 || got > 0.3+1e-9 {
+		t.Errorf("expected total risk 0.3, got %v", got)
+	}
+
+	labels := net.ComponentLabels()
+	if labels[4] != 0 || labels[2] != 1 || labels[7] != 3 {
+		t.Errorf("unexpected labels %v", labels)
+	}
+}
+
+func TestSubnetwork(t *testing.T) {
+	net := New()
+	for i := 1; i <= 4; i++ {
+		net.AddNode(&Node{ID: ID(i), Length: 1})
+	}
+	net.AddUndirectedEdge(1, 2)
+	net.AddUndirectedEdge(2, 3)
+	net.AddUndirectedEdge(3, 4)
+
+	sub := net.Subnetwork([]ID{2, 3, 42})
+
+	if len(sub.Nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(sub.Nodes))
+	}
+	expected := map[ID][]ID{2: {3}, 3: {2}}
+	if !reflect.DeepEqual(sub.Edges, expected) {
+		t.Errorf("expected edges %v, got %v", expected, sub.Edges)
+	}
+	if !sub.Validate().Valid() {
+		t.Errorf("expected subnetwork to be valid, got %v", sub.Validate().Issues)
+	}
+}
@@ -0,0 +1,60 @@
+package graph
+
+// CSR is a frozen, read-optimised copy of a Network in compressed sparse row
+// form. Pipes get dense int32 indices 0..Len()-1 in ascending ID order and
+// their lengths and scores are stored in contiguous arrays. Edges to pipes
+// that do not exist are dropped.
+//
+// A CSR does not follow later changes to the Network it was built from; it
+// is safe for concurrent reads.
+type CSR struct {
+	IDs     []ID      // Dense index -> pipe ID
+	Offsets []int32   // Neighbours of index i are Adj[Offsets[i]:Offsets[i+1]]
+	Adj     []int32   // Concatenated neighbour indices
+	Length  []float64 // Pipe length by index
+	Score   []float64 // Pipe score by index
+
+	index map[ID]int32
+}
+
+// NewCSR builds the CSR form of n.
+func NewCSR(n *Network) *CSR {
+	ids := sortedIDs(n.Nodes)
+	c := &CSR{
+		IDs:     ids,
+		Offsets: make([]int32, len(ids)+1),
+		Length:  make([]float64, len(ids)),
+		Score:   make([]float64, len(ids)),
+		index:   make(map[ID]int32, len(ids)),
+	}
+	for i, id := range ids {
+		c.index[id] = int32(i)
+		c.Length[i] = n.Nodes[id].Length
+		c.Score[i] = n.Nodes[id].Score
+	}
+
+	for i, id := range ids {
+		for _, nbr := range n.Edges[id] {
+			if j, ok := c.index[nbr]; ok {
+				c.Adj = append(c.Adj, j)
+			}
+		}
+		c.Offsets[i+1] = int32(len(c.Adj))
+	}
+	return c
+}
+
+// Len returns the number of pipes.
+func (c *CSR) Len() int { return len(c.IDs) }
+
+// Index returns the dense index of the pipe with the given ID.
+func (c *CSR) Index(id ID) (int32, bool) {
+	i, ok := c.index[id]
+	return i, ok
+}
+
+// Neighbours returns the dense indices of the pipes connected to index i.
+// The returned slice aliases the CSR and must not be modified.
+func (c *CSR) Neighbours(i int32) []int32 {
+	return c.Adj[c.Offsets[i]:c.Offsets[i+1]]
+}
@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewCSR(t *testing.T) {
+	net := New()
+	net.AddNode(&Node{ID: 30, Score: 0.3, Length: 3})
+	net.AddNode(&Node{ID: 10, Score: 0.1, Length: 1})
+	net.AddNode(&Node{ID: 20, Score: 0.2, Length: 2})
+	net.AddUndirectedEdge(10, 20)
+	net.AddUndirectedEdge(20, 30)
+	net.AddUndirectedEdge(30, 40) // dangling, dropped
+
+	c := NewCSR(net)
+
+	if c.Len() != 3 {
+		t.Fatalf("expected 3 pipes, got %d", c.Len())
+	}
+	if !reflect.DeepEqual(c.IDs, []ID{10, 20, 30}) {
+		t.Errorf("expected IDs in ascending order, got %v", c.IDs)
+	}
+	if !reflect.DeepEqual(c.Length, []float64{1, 2, 3}) || !reflect.DeepEqual(c.Score, []float64{0.1, 0.2, 0.3}) {
+		t.Errorf("unexpected length/score arrays %v %v", c.Length, c.Score)
+	}
+
+	expected := [][]int32{{1}, {0, 2}, {1}}
+	for i, want := range expected {
+		if got := c.Neighbours(int32(i)); !reflect.DeepEqual(got, want) {
+			t.Errorf("neighbours of %d: expected %v, got %v", i, want, got)
+		}
+	}
+
+	if i, ok := c.Index(20); !ok || i != 1 {
+		t.Errorf("expected index 1 for ID 20, got %d (ok=%v)", i, ok)
+	}
+	if _, ok := c.Index(40); ok {
+		t.Errorf("expected no index for missing ID 40")
+	}
+}
@@ -0,0 +1,345 @@
+package graph
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Column names used by the CSV import/export.
+const (
+	ColID     = "id"
+	ColScore  = "score"
+	ColLength = "length"
+	ColFrom   = "from"
+	ColTo     = "to"
+)
+
+// ParseError reports a problem at a specific line of an imported file.
+type ParseError struct {
+	Source string // Which input the error came from, e.g. "pipes"
+	Line   int    // 1-based line number
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s line %d: %v", e.Source, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// ReadCSV builds a Network from a pipes table and a connections table.
+//
+// The pipes table needs a header row with at least the columns id, score and
+// length (in any order, case-insensitive). Columns named after a typed
+// attribute (material, diameter, install_year, zone, start_x, start_y, end_x,
+// end_y) fill the matching Node field; any other column is stored in
+// Node.Attrs under its header name. Empty cells are skipped. The connections
+// table needs the columns from and to, one undirected edge per row.
+func ReadCSV(pipes, edges io.Reader) (*Network, error) {
+	net := New()
+	if err := readPipes(net, pipes); err != nil {
+		return nil, err
+	}
+	if err := readEdges(net, edges); err != nil {
+		return nil, err
+	}
+	return net, nil
+}
+
+// LoadCSV is ReadCSV for files on disk.
+func LoadCSV(pipesPath, edgesPath string) (*Network, error) {
+	pf, err := os.Open(pipesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer pf.Close()
+
+	ef, err := os.Open(edgesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer ef.Close()
+
+	return ReadCSV(pf, ef)
+}
+
+func readPipes(net *Network, r io.Reader) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return &ParseError{Source: "pipes", Line: 1, Err: headerErr(err)}
+	}
+	cols, err := columnIndex(header, ColID, ColScore, ColLength)
+	if err != nil {
+		return &ParseError{Source: "pipes", Line: 1, Err: err}
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return &ParseError{Source: "pipes", Line: readErrLine(err), Err: err}
+		}
+		line, _ := cr.FieldPos(0)
+
+		id, err := parseID(record[cols[ColID]])
+		if err != nil {
+			return &ParseError{Source: "pipes", Line: line, Err: err}
+		}
+		if _, dup := net.Nodes[id]; dup {
+			return &ParseError{Source: "pipes", Line: line, Err: fmt.Errorf("duplicate id %d", id)}
+		}
+		score, err := parseFloat(ColScore, record[cols[ColScore]])
+		if err != nil {
+			return &ParseError{Source: "pipes", Line: line, Err: err}
+		}
+		length, err := parseFloat(ColLength, record[cols[ColLength]])
+		if err != nil {
+			return &ParseError{Source: "pipes", Line: line, Err: err}
+		}
+
+		node := &Node{ID: id, Score: score, Length: length}
+		for i, value := range record {
+			name := strings.TrimSpace(header[i])
+			if isCoreColumn(name) || value == "" {
+				continue
+			}
+			if lower := strings.ToLower(name); IsTypedAttr(lower) {
+				name = lower
+			}
+			if err := node.SetAttr(name, value); err != nil {
+				return &ParseError{Source: "pipes", Line: line, Err: err}
+			}
+		}
+		net.AddNode(node)
+	}
+}
+
+func readEdges(net *Network, r io.Reader) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return &ParseError{Source: "edges", Line: 1, Err: headerErr(err)}
+	}
+	cols, err := columnIndex(header, ColFrom, ColTo)
+	if err != nil {
+		return &ParseError{Source: "edges", Line: 1, Err: err}
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return &ParseError{Source: "edges", Line: readErrLine(err), Err: err}
+		}
+		line, _ := cr.FieldPos(0)
+
+		var ends [2]ID
+		for i, col := range []string{ColFrom, ColTo} {
+			id, err := parseID(record[cols[col]])
+			if err != nil {
+				return &ParseError{Source: "edges", Line: line, Err: err}
+			}
+			if _, ok := net.Nodes[id]; !ok {
+				return &ParseError{Source: "edges", Line: line, Err: fmt.Errorf("%s references unknown pipe %d", col, id)}
+			}
+			ends[i] = id
+		}
+		net.AddUndirectedEdge(ends[0], ends[1])
+	}
+}
+
+// WriteCSV writes net as a pipes table and a connections table that ReadCSV
+// turns back into an equivalent Network, up to the order of each pipe's
+// neighbours. Pipes are written in ID order with one column per typed or
+// custom attribute set on any node; every undirected edge is written once,
+// from its lower pipe ID, so the neighbours of a pipe are read back ordered
+// by pipe ID rather than by when their edges were added.
+func WriteCSV(net *Network, pipes, edges io.Writer) error {
+	if err := writePipes(net, pipes); err != nil {
+		return err
+	}
+	return writeEdges(net, edges)
+}
+
+// SaveCSV is WriteCSV for files on disk.
+func SaveCSV(net *Network, pipesPath, edgesPath string) error {
+	pf, err := os.Create(pipesPath)
+	if err != nil {
+		return err
+	}
+	ef, err := os.Create(edgesPath)
+	if err != nil {
+		pf.Close()
+		return err
+	}
+
+	err = WriteCSV(net, pf, ef)
+	if cerr := pf.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := ef.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func writePipes(net *Network, w io.Writer) error {
+	var keys []string
+	for _, k := range typedAttrs {
+		for _, node := range net.Nodes {
+			if _, ok := node.Attr(k); ok {
+				keys = append(keys, k)
+				break
+			}
+		}
+	}
+
+	keySet := make(map[string]struct{})
+	for _, node := range net.Nodes {
+		for k := range node.Attrs {
+			keySet[k] = struct{}{}
+		}
+	}
+	custom := make([]string, 0, len(keySet))
+	for k := range keySet {
+		custom = append(custom, k)
+	}
+	sort.Strings(custom)
+	keys = append(keys, custom...)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{ColID, ColScore, ColLength}, keys...)); err != nil {
+		return err
+	}
+	for _, id := range sortedIDs(net.Nodes) {
+		node := net.Nodes[id]
+		record := []string{
+			strconv.FormatInt(int64(node.ID), 10),
+			formatFloat(node.Score),
+			formatFloat(node.Length),
+		}
+		for _, k := range keys {
+			v, _ := node.Attr(k)
+			record = append(record, v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeEdges(net *Network, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{ColFrom, ColTo}); err != nil {
+		return err
+	}
+	for _, id := range sortedIDs(net.Edges) {
+		selfLoops := 0
+		for _, nbr := range net.Edges[id] {
+			if nbr == id {
+				// AddUndirectedEdge stores a self-loop twice
+				selfLoops++
+				if selfLoops%2 == 0 {
+					continue
+				}
+			} else if nbr < id {
+				continue
+			}
+			record := []string{
+				strconv.FormatInt(int64(id), 10),
+				strconv.FormatInt(int64(nbr), 10),
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// columnIndex maps each required column name to its position in header.
+func columnIndex(header []string, required ...string) (map[string]int, error) {
+	cols := make(map[string]int, len(required))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, seen := cols[name]; seen {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		cols[name] = i
+	}
+	for _, name := range required {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	return cols, nil
+}
+
+func isCoreColumn(name string) bool {
+	switch strings.ToLower(name) {
+	case ColID, ColScore, ColLength:
+		return true
+	}
+	return false
+}
+
+// readErrLine returns the line a failed csv.Reader.Read reports, or 0 if
+// the error carries none. FieldPos must not be used then: it is only valid
+// after a successful Read.
+func readErrLine(err error) int {
+	var perr *csv.ParseError
+	if !errors.As(err, &perr) {
+		return 0
+	}
+	if perr.StartLine > 0 {
+		return perr.StartLine
+	}
+	return perr.Line
+}
+
+func headerErr(err error) error {
+	if err == io.EOF {
+		return errors.New("missing header row")
+	}
+	return err
+}
+
+func parseID(s string) (ID, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q", s)
+	}
+	return ID(v), nil
+}
+
+func parseFloat(col, s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", col, s)
+	}
+	return v, nil
+}
+
+// sortedIDs returns the keys of m in ascending order.
+func sortedIDs[V any](m map[ID]V) []ID {
+	ids := make([]ID, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	net := New()
+	net.AddNode(&Node{ID: 1, Score: 0.5, Length: 12.25, Material: "PVC", Diameter: 110, InstallYear: 1998,
+		Start: &Point{X: 4.35, Y: 50.85}, End: &Point{X: 4.3501, Y: 50.85}})
+	net.AddNode(&Node{ID: 2, Score: 0.1, Length: 7, Material: "cast iron", Zone: "north",
+		Attrs: map[string]string{"street": "Main St, North"}})
+	net.AddNode(&Node{ID: 3, Score: 0.333333333333, Length: 1e-3})
+	net.AddNode(&Node{ID: 10, Score: 0, Length: 4}) // isolated
+	net.AddUndirectedEdge(1, 2)
+	net.AddUndirectedEdge(2, 3)
+	net.AddUndirectedEdge(3, 1)
+	net.AddUndirectedEdge(1, 2) // parallel edge
+
+	var pipes, edges bytes.Buffer
+	if err := WriteCSV(net, &pipes, &edges); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	got, err := ReadCSV(&pipes, &edges)
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+
+	if !reflect.DeepEqual(net.Nodes, got.Nodes) {
+		t.Errorf("nodes differ after round trip:\nwant %v\ngot  %v", net.Nodes, got.Nodes)
+	}
+	// WriteCSV keeps the connections but not the order of each neighbour list
+	if !reflect.DeepEqual(sortedAdjacency(net), sortedAdjacency(got)) {
+		t.Errorf("edges differ after round trip:\nwant %v\ngot  %v", net.Edges, got.Edges)
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		pipes  string
+		edges  string
+		source string
+		line   int
+		msg    string
+	}{
+		{
+			name:   "missing column",
+			pipes:  "id,score\n1,0.5\n",
+			edges:  "from,to\n",
+			source: "pipes",
+			line:   1,
+			msg:    `missing column "length"`,
+		},
+		{
+			name:   "duplicate id",
+			pipes:  "id,score,length\n1,0.5,1\n2,0.5,1\n1,0.2,3\n",
+			edges:  "from,to\n",
+			source: "pipes",
+			line:   4,
+			msg:    "duplicate id 1",
+		},
+		{
+			name:   "non-numeric length",
+			pipes:  "id,score,length\n1,0.5,1\n2,0.5,ten\n",
+			edges:  "from,to\n",
+			source: "pipes",
+			line:   3,
+			msg:    `invalid length "ten"`,
+		},
+		{
+			name:   "non-numeric install year",
+			pipes:  "id,score,length,Install_Year\n1,0.5,1,1970\n2,0.5,1,old\n",
+			edges:  "from,to\n",
+			source: "pipes",
+			line:   3,
+			msg:    `invalid install_year "old"`,
+		},
+		{
+			// The quote is never closed, so the record runs to the end of
+			// the file
+			name:   "unterminated quote",
+			pipes:  "id,score,length\n\"1,0.5,1\n",
+			edges:  "from,to\n",
+			source: "pipes",
+			line:   2,
+			msg:    `extraneous or missing " in quoted-field`,
+		},
+		{
+			name:   "unterminated quote in edges",
+			pipes:  "id,score,length\n1,0.5,1\n2,0.5,1\n",
+			edges:  "from,to\n1,2\n\"2,1\n",
+			source: "edges",
+			line:   3,
+			msg:    `extraneous or missing " in quoted-field`,
+		},
+		{
+			name:   "dangling endpoint",
+			pipes:  "id,score,length\n1,0.5,1\n2,0.5,1\n",
+			edges:  "from,to\n1,2\n2,7\n",
+			source: "edges",
+			line:   3,
+			msg:    "to references unknown pipe 7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadCSV(strings.NewReader(tt.pipes), strings.NewReader(tt.edges))
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *ParseError, got %v", err)
+			}
+			if perr.Source != tt.source || perr.Line != tt.line {
+				t.Errorf("expected %s line %d, got %s line %d", tt.source, tt.line, perr.Source, perr.Line)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("expected error to contain %q, got %q", tt.msg, err.Error())
+			}
+		})
+	}
+}
+
+// sortedAdjacency returns a copy of net.Edges with every neighbour list
+// sorted, so networks can be compared independent of insertion order.
+func sortedAdjacency(net *Network) map[ID][]ID {
+	adj := make(map[ID][]ID, len(net.Edges))
+	for id, nbrs := range net.Edges {
+		s := append([]ID(nil), nbrs...)
+		sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+		adj[id] = s
+	}
+	return adj
+}
@@ -0,0 +1,253 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Attribute keys set by ReadEPANET.
+const (
+	AttrEPANETID  = "epanet_id" // Original pipe ID from the INP file
+	AttrNode1     = "node1"     // Junction/reservoir/tank at the start of the pipe
+	AttrNode2     = "node2"     // Junction/reservoir/tank at the end of the pipe
+	AttrRoughness = "roughness" // Roughness coefficient from the INP file
+)
+
+// inpRecord is one data line of an INP section.
+type inpRecord struct {
+	line   int
+	fields []string
+}
+
+// ReadEPANET builds a Network from an EPANET input (.inp) file.
+//
+// Every entry of [PIPES] becomes a Node with its Length taken from the file.
+// Pipes keep their INP ID as Node.ID when all pipe IDs are distinct integers
+// and are numbered from 1 in file order otherwise (also when IDs such as "1"
+// and "01" would give the same number); the original ID, the IDs of both
+// end nodes and the roughness are kept in Node.Attrs and the diameter in
+// Node.Diameter. Two pipes are connected when they share a junction,
+// reservoir or tank. Valves join the nodes at either end, so pipes on both
+// sides of a valve are connected too. Node coordinates from [COORDINATES]
+// become Node.Start/End of the pipes that end there.
+func ReadEPANET(r io.Reader) (*Network, error) {
+	sections, err := readINPSections(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hydraulic nodes, merged across valves
+	junctions := newUnionFind()
+	for _, name := range []string{"JUNCTIONS", "RESERVOIRS", "TANKS"} {
+		for _, rec := range sections[name] {
+			if _, dup := junctions.parent[rec.fields[0]]; dup {
+				return nil, &ParseError{Source: "inp", Line: rec.line, Err: fmt.Errorf("duplicate node %q", rec.fields[0])}
+			}
+			junctions.add(rec.fields[0])
+		}
+	}
+	for _, rec := range sections["VALVES"] {
+		if len(rec.fields) < 3 {
+			return nil, &ParseError{Source: "inp", Line: rec.line, Err: fmt.Errorf("valve needs ID, Node1 and Node2")}
+		}
+		for _, n := range rec.fields[1:3] {
+			if _, ok := junctions.parent[n]; !ok {
+				return nil, &ParseError{Source: "inp", Line: rec.line, Err: fmt.Errorf("valve %q references unknown node %q", rec.fields[0], n)}
+			}
+		}
+		junctions.union(rec.fields[1], rec.fields[2])
+	}
+
+	coords := make(map[string][2]string)
+	for _, rec := range sections["COORDINATES"] {
+		if len(rec.fields) < 3 {
+			return nil, &ParseError{Source: "inp", Line: rec.line, Err: fmt.Errorf("coordinates need Node, X and Y")}
+		}
+		if _, ok := junctions.parent[rec.fields[0]]; !ok {
+			return nil, &ParseError{Source: "inp", Line: rec.line, Err: fmt.Errorf("coordinates for unknown node %q", rec.fields[0])}
+		}
+		coords[rec.fields[0]] = [2]string{rec.fields[1], rec.fields[2]}
+	}
+
+	pipes := sections["PIPES"]
+	numericIDs := true
+	parsed := make(map[ID]string) // numeric ID -> INP ID it came from
+	for _, rec := range pipes {
+		v, err := strconv.ParseInt(rec.fields[0], 10, 64)
+		if err != nil {
+			numericIDs = false
+			break
+		}
+		// "01", "+1" and "1" are different pipes with the same number
+		if prev, ok := parsed[ID(v)]; ok && prev != rec.fields[0] {
+			numericIDs = false
+			break
+		}
+		parsed[ID(v)] = rec.fields[0]
+	}
+
+	net := New()
+	seen := make(map[string]bool)
+	atJunction := make(map[string][]ID) // junction group -> pipes ending there
+
+	for i, rec := range pipes {
+		if len(rec.fields) < 4 {
+			return nil, &ParseError{Source: "inp", Line: rec.line, Err: fmt.Errorf("pipe needs ID, Node1, Node2 and Length")}
+		}
+		name, n1, n2 := rec.fields[0], rec.fields[1], rec.fields[2]
+		if seen[name] {
+			return nil, &ParseError{Source: "inp", Line: rec.line, Err: fmt.Errorf("duplicate pipe %q", name)}
+		}
+		seen[name] = true
+
+		for _, n := range []string{n1, n2} {
+			if _, ok := junctions.parent[n]; !ok {
+				return nil, &ParseError{Source: "inp", Line: rec.line, Err: fmt.Errorf("pipe %q references unknown node %q", name, n)}
+			}
+		}
+		length, err := parseFloat(ColLength, rec.fields[3])
+		if err != nil {
+			return nil, &ParseError{Source: "inp", Line: rec.line, Err: err}
+		}
+
+		id := ID(i + 1)
+		if numericIDs {
+			v, _ := strconv.ParseInt(name, 10, 64)
+			id = ID(v)
+		}
+
+		node := &Node{
+			ID:     id,
+			Length: length,
+			Attrs:  map[string]string{AttrEPANETID: name, AttrNode1: n1, AttrNode2: n2},
+		}
+		var set [][2]string
+		if len(rec.fields) > 4 {
+			set = append(set, [2]string{AttrDiameter, rec.fields[4]})
+		}
+		if len(rec.fields) > 5 {
+			set = append(set, [2]string{AttrRoughness, rec.fields[5]})
+		}
+		if c, ok := coords[n1]; ok {
+			set = append(set, [2]string{AttrStartX, c[0]}, [2]string{AttrStartY, c[1]})
+		}
+		if c, ok := coords[n2]; ok {
+			set = append(set, [2]string{AttrEndX, c[0]}, [2]string{AttrEndY, c[1]})
+		}
+		for _, kv := range set {
+			if err := node.SetAttr(kv[0], kv[1]); err != nil {
+				return nil, &ParseError{Source: "inp", Line: rec.line, Err: err}
+			}
+		}
+		net.AddNode(node)
+
+		g1, g2 := junctions.find(n1), junctions.find(n2)
+		atJunction[g1] = append(atJunction[g1], id)
+		if g2 != g1 {
+			atJunction[g2] = append(atJunction[g2], id)
+		}
+	}
+
+	// Connect every pair of pipes meeting at the same junction, visiting
+	// junctions in file order so the adjacency order is reproducible.
+	linked := make(map[[2]ID]bool)
+	for _, name := range junctions.order {
+		if junctions.find(name) != name {
+			continue
+		}
+		ids := atJunction[name]
+		for a := 0; a < len(ids); a++ {
+			for b := a + 1; b < len(ids); b++ {
+				pair := [2]ID{ids[a], ids[b]}
+				if pair[0] > pair[1] {
+					pair[0], pair[1] = pair[1], pair[0]
+				}
+				if !linked[pair] {
+					linked[pair] = true
+					net.AddUndirectedEdge(pair[0], pair[1])
+				}
+			}
+		}
+	}
+	return net, nil
+}
+
+// LoadEPANET is ReadEPANET for a file on disk.
+func LoadEPANET(path string) (*Network, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadEPANET(f)
+}
+
+// readINPSections splits an INP file into its sections, dropping comments
+// and blank lines. Section names are upper-cased without brackets.
+func readINPSections(r io.Reader) (map[string][]inpRecord, error) {
+	sections := make(map[string][]inpRecord)
+	section := ""
+	sc := bufio.NewScanner(r)
+	line := 0
+
+	for sc.Scan() {
+		line++
+		text := sc.Text()
+		if i := strings.IndexByte(text, ';'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, &ParseError{Source: "inp", Line: line, Err: fmt.Errorf("malformed section header %q", text)}
+			}
+			section = strings.ToUpper(strings.TrimSpace(text[1 : len(text)-1]))
+			continue
+		}
+		if section == "" {
+			return nil, &ParseError{Source: "inp", Line: line, Err: fmt.Errorf("data outside of a section")}
+		}
+		sections[section] = append(sections[section], inpRecord{line: line, fields: strings.Fields(text)})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// unionFind groups hydraulic node IDs that are joined by valves.
+type unionFind struct {
+	parent map[string]string
+	order  []string // insertion order, for reproducible iteration
+}
+
+func newUnionFind() *unionFind {
+	return &unionFind{parent: make(map[string]string)}
+}
+
+func (u *unionFind) add(x string) {
+	u.parent[x] = x
+	u.order = append(u.order, x)
+}
+
+func (u *unionFind) find(x string) string {
+	for u.parent[x] != x {
+		u.parent[x] = u.parent[u.parent[x]]
+		x = u.parent[x]
+	}
+	return x
+}
+
+func (u *unionFind) union(a, b string) {
+	ra, rb := u.find(a), u.find(b)
+	if ra != rb {
+		u.parent[rb] = ra
+	}
+}
@@ -0,0 +1,172 @@
+package graph
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// R1 feeds J1; pipes P1..P3 form a chain with a valve between J3 and J4.
+//
+//	R1 --10-- J1 --11-- J2 --12-- J3 =V1= J4 --13-- J5
+const testINP = `[TITLE]
+Test network
+
+[JUNCTIONS]
+;ID   Elev  Demand
+ J1   10    0
+ J2   10    1.5
+ J3   9     0
+ J4   9     0
+ J5   8     2  ; dead end
+
+[RESERVOIRS]
+ R1   50
+
+[PIPES]
+;ID  Node1  Node2  Length  Diameter  Roughness
+ 10  R1     J1     120.5   300       100
+ 11  J1     J2     80      200       100
+ 12  J2     J3     45.25   150       100
+ 13  J4     J5     60      100       100
+
+[VALVES]
+ V1  J3  J4  150  PRV  40  0
+
+[COORDINATES]
+ J1  10.0  20.0
+ J2  30.0  20.0
+
+[END]
+`
+
+func TestReadEPANET(t *testing.T) {
+	net, err := ReadEPANET(strings.NewReader(testINP))
+	if err != nil {
+		t.Fatalf("ReadEPANET: %v", err)
+	}
+
+	if len(net.Nodes) != 4 {
+		t.Fatalf("expected 4 pipes, got %d", len(net.Nodes))
+	}
+	expected := map[ID][]ID{
+		10: {11},
+		11: {10, 12},
+		12: {11, 13}, // through the valve
+		13: {12},
+	}
+	if got := sortedAdjacency(net); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected edges %v, got %v", expected, got)
+	}
+
+	p := net.Nodes[11]
+	if p.Length != 80 {
+		t.Errorf("expected pipe 11 length 80, got %v", p.Length)
+	}
+	wantAttrs := map[string]string{
+		AttrEPANETID: "11", AttrNode1: "J1", AttrNode2: "J2", AttrRoughness: "100",
+	}
+	if !reflect.DeepEqual(p.Attrs, wantAttrs) {
+		t.Errorf("expected attrs %v, got %v", wantAttrs, p.Attrs)
+	}
+	if p.Diameter != 200 {
+		t.Errorf("expected diameter 200, got %v", p.Diameter)
+	}
+	if *p.Start != (Point{X: 10, Y: 20}) || *p.End != (Point{X: 30, Y: 20}) {
+		t.Errorf("expected endpoints from [COORDINATES], got %v and %v", p.Start, p.End)
+	}
+	if net.Nodes[13].Start != nil {
+		t.Errorf("expected no coordinates for pipe 13, got %v", net.Nodes[13].Start)
+	}
+}
+
+func TestReadEPANETNonNumericIDs(t *testing.T) {
+	input := `[JUNCTIONS]
+A 0
+B 0
+C 0
+[PIPES]
+main-1 A B 10
+main-2 B C 20
+`
+	net, err := ReadEPANET(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadEPANET: %v", err)
+	}
+	if net.Nodes[1].Attrs[AttrEPANETID] != "main-1" || net.Nodes[2].Attrs[AttrEPANETID] != "main-2" {
+		t.Errorf("expected pipes numbered in file order, got %v and %v", net.Nodes[1], net.Nodes[2])
+	}
+	if !reflect.DeepEqual(net.Edges[1], []ID{2}) {
+		t.Errorf("expected pipe 1 connected to 2, got %v", net.Edges[1])
+	}
+}
+
+func TestReadEPANETCollidingIDs(t *testing.T) {
+	// "01" and "1" both parse as 1 but are different pipes
+	input := `[JUNCTIONS]
+A 0
+B 0
+C 0
+[PIPES]
+01 A B 10
+1 B C 20
+`
+	net, err := ReadEPANET(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadEPANET: %v", err)
+	}
+	if len(net.Nodes) != 2 {
+		t.Fatalf("expected 2 pipes, got %d", len(net.Nodes))
+	}
+	if net.Nodes[1].Attrs[AttrEPANETID] != "01" || net.Nodes[2].Attrs[AttrEPANETID] != "1" {
+		t.Errorf("expected pipes numbered in file order, got %v and %v", net.Nodes[1], net.Nodes[2])
+	}
+	if !reflect.DeepEqual(net.Edges[1], []ID{2}) || !reflect.DeepEqual(net.Edges[2], []ID{1}) {
+		t.Errorf("expected pipes 1 and 2 connected once, got %v", net.Edges)
+	}
+}
+
+func TestReadEPANETErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+		msg   string
+	}{
+		{
+			name:  "unknown node",
+			input: "[JUNCTIONS]\nJ1 0\n[PIPES]\nP1 J1 J9 10\n",
+			line:  4,
+			msg:   `pipe "P1" references unknown node "J9"`,
+		},
+		{
+			name:  "non-numeric length",
+			input: "[JUNCTIONS]\nJ1 0\nJ2 0\n[PIPES]\nP1 J1 J2 long\n",
+			line:  5,
+			msg:   `invalid length "long"`,
+		},
+		{
+			name:  "duplicate pipe",
+			input: "[JUNCTIONS]\nJ1 0\nJ2 0\n[PIPES]\nP1 J1 J2 1\nP1 J2 J1 1\n",
+			line:  6,
+			msg:   `duplicate pipe "P1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadEPANET(strings.NewReader(tt.input))
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *ParseError, got %v", err)
+			}
+			if perr.Line != tt.line {
+				t.Errorf("expected line %d, got %d", tt.line, perr.Line)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("expected error to contain %q, got %q", tt.msg, err.Error())
+			}
+		})
+	}
+}
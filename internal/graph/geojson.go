@@ -0,0 +1,315 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// earthRadius is the mean Earth radius in metres.
+const earthRadius = 6371008.8
+
+// GeoJSONOptions controls how ReadGeoJSON maps features onto pipes.
+type GeoJSONOptions struct {
+	SnapTolerance float64 // Pipes whose endpoints lie within this many metres are connected
+	ScoreProperty string  // Numeric property used as Node.Score (empty leaves scores at 0)
+	IDProperty    string  // Integer property used as Node.ID (empty uses the feature id, then its position)
+}
+
+type geoFeatureCollection struct {
+	Type     string       `json:"type"`
+	Features []geoFeature `json:"features"`
+}
+
+type geoFeature struct {
+	ID         interface{}            `json:"id"`
+	Geometry   *geoGeometry           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// lonLat is a WGS84 position in degrees.
+type lonLat [2]float64
+
+// ReadGeoJSON builds a Network from a FeatureCollection of LineString (or
+// MultiLineString) features, one pipe per feature.
+//
+// Node.Length is the geodesic length of the geometry in metres and
+// Node.Start/End are its first and last positions. Two pipes are connected
+// when one of their endpoints lies within SnapTolerance metres of one of the
+// other's endpoints. Properties named after a typed attribute (material,
+// diameter, install_year, zone) fill the matching Node field; other scalar
+// properties besides the score and ID properties are kept in Node.Attrs.
+// Properties named start_x, start_y, end_x or end_y are ignored, since the
+// endpoints come from the geometry. Properties are read in name order, so
+// the first invalid one by name is reported.
+func ReadGeoJSON(r io.Reader, opts GeoJSONOptions) (*Network, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var fc geoFeatureCollection
+	if err := dec.Decode(&fc); err != nil {
+		return nil, fmt.Errorf("geojson: %w", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("geojson: expected a FeatureCollection, got %q", fc.Type)
+	}
+
+	net := New()
+	var ends []pipeEnd
+
+	for i, f := range fc.Features {
+		id, err := featureID(f, i, opts.IDProperty)
+		if err != nil {
+			return nil, fmt.Errorf("geojson: feature %d: %w", i, err)
+		}
+		if _, dup := net.Nodes[id]; dup {
+			return nil, fmt.Errorf("geojson: feature %d: duplicate id %d", i, id)
+		}
+
+		lines, err := featureLines(f)
+		if err != nil {
+			return nil, fmt.Errorf("geojson: feature %d: %w", i, err)
+		}
+
+		node := &Node{ID: id}
+		for _, line := range lines {
+			node.Length += lineLength(line)
+		}
+		first, last := lines[0][0], lines[len(lines)-1][len(lines[len(lines)-1])-1]
+		ends = append(ends, pipeEnd{id: id, pos: first}, pipeEnd{id: id, pos: last})
+		node.Start = &Point{X: first[0], Y: first[1]}
+		node.End = &Point{X: last[0], Y: last[1]}
+
+		keys := make([]string, 0, len(f.Properties))
+		for k := range f.Properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := f.Properties[k]
+			switch k {
+			case opts.IDProperty, AttrStartX, AttrStartY, AttrEndX, AttrEndY:
+				continue
+			case opts.ScoreProperty:
+				score, err := propertyFloat(v)
+				if err != nil {
+					return nil, fmt.Errorf("geojson: feature %d: property %q: %w", i, k, err)
+				}
+				node.Score = score
+				continue
+			}
+			if s, ok := propertyString(v); ok {
+				if err := node.SetAttr(k, s); err != nil {
+					return nil, fmt.Errorf("geojson: feature %d: %w", i, err)
+				}
+			}
+		}
+		net.AddNode(node)
+	}
+
+	for _, pair := range snapEndpoints(ends, opts.SnapTolerance) {
+		net.AddUndirectedEdge(pair[0], pair[1])
+	}
+	return net, nil
+}
+
+// LoadGeoJSON is ReadGeoJSON for a file on disk.
+func LoadGeoJSON(path string, opts GeoJSONOptions) (*Network, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGeoJSON(f, opts)
+}
+
+func featureID(f geoFeature, index int, prop string) (ID, error) {
+	if prop != "" {
+		v, ok := f.Properties[prop]
+		if !ok {
+			return 0, fmt.Errorf("missing id property %q", prop)
+		}
+		return propertyID(v)
+	}
+	if f.ID != nil {
+		if id, err := propertyID(f.ID); err == nil {
+			return id, nil
+		}
+	}
+	return ID(index), nil
+}
+
+func featureLines(f geoFeature) ([][]lonLat, error) {
+	if f.Geometry == nil {
+		return nil, fmt.Errorf("missing geometry")
+	}
+
+	var raw [][][]float64
+	switch f.Geometry.Type {
+	case "LineString":
+		var line [][]float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &line); err != nil {
+			return nil, fmt.Errorf("invalid coordinates: %w", err)
+		}
+		raw = [][][]float64{line}
+	case "MultiLineString":
+		if err := json.Unmarshal(f.Geometry.Coordinates, &raw); err != nil {
+			return nil, fmt.Errorf("invalid coordinates: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", f.Geometry.Type)
+	}
+
+	lines := make([][]lonLat, 0, len(raw))
+	for _, line := range raw {
+		if len(line) < 2 {
+			return nil, fmt.Errorf("line with fewer than 2 positions")
+		}
+		pts := make([]lonLat, len(line))
+		for i, p := range line {
+			if len(p) < 2 {
+				return nil, fmt.Errorf("position with fewer than 2 coordinates")
+			}
+			pts[i] = lonLat{p[0], p[1]}
+		}
+		lines = append(lines, pts)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("empty geometry")
+	}
+	return lines, nil
+}
+
+func propertyID(v interface{}) (ID, error) {
+	var s string
+	switch x := v.(type) {
+	case json.Number:
+		s = x.String()
+	case string:
+		s = x
+	default:
+		return 0, fmt.Errorf("invalid id %v", v)
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q", s)
+	}
+	return ID(id), nil
+}
+
+func propertyFloat(v interface{}) (float64, error) {
+	switch x := v.(type) {
+	case json.Number:
+		return x.Float64()
+	case string:
+		return strconv.ParseFloat(x, 64)
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("not a number: %v", v)
+}
+
+// propertyString renders scalar property values; nested objects and arrays
+// are not kept.
+func propertyString(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case json.Number:
+		return x.String(), true
+	case bool:
+		return strconv.FormatBool(x), true
+	}
+	return "", false
+}
+
+// lineLength returns the geodesic length of a polyline in metres.
+func lineLength(line []lonLat) float64 {
+	var total float64
+	for i := 1; i < len(line); i++ {
+		total += haversine(line[i-1], line[i])
+	}
+	return total
+}
+
+// haversine returns the great-circle distance between a and b in metres.
+func haversine(a, b lonLat) float64 {
+	lat1, lat2 := a[1]*math.Pi/180, b[1]*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b[0] - a[0]) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// pipeEnd is one endpoint of an imported pipe.
+type pipeEnd struct {
+	id  ID
+	pos lonLat
+}
+
+// snapEndpoints returns every pair of distinct pipes with endpoints within
+// tol metres of each other, each pair once and in a stable order.
+//
+// Endpoints are bucketed on a grid in a local equirectangular projection so
+// only neighbouring cells need comparing. Cells are twice the tolerance wide
+// to absorb the projection's distortion away from the mean latitude.
+func snapEndpoints(ends []pipeEnd, tol float64) [][2]ID {
+	if len(ends) == 0 {
+		return nil
+	}
+	cell := 2 * tol
+	if cell <= 0 {
+		cell = 1
+	}
+
+	var meanLat float64
+	for _, e := range ends {
+		meanLat += e.pos[1]
+	}
+	cosLat := math.Cos(meanLat / float64(len(ends)) * math.Pi / 180)
+
+	key := func(p lonLat) [2]int64 {
+		x := p[0] * math.Pi / 180 * earthRadius * cosLat
+		y := p[1] * math.Pi / 180 * earthRadius
+		return [2]int64{int64(math.Floor(x / cell)), int64(math.Floor(y / cell))}
+	}
+
+	grid := make(map[[2]int64][]int)
+	seen := make(map[[2]ID]bool)
+	var pairs [][2]ID
+
+	for i, e := range ends {
+		k := key(e.pos)
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for _, j := range grid[[2]int64{k[0] + dx, k[1] + dy}] {
+					other := ends[j]
+					if other.id == e.id || haversine(e.pos, other.pos) > tol {
+						continue
+					}
+					pair := [2]ID{other.id, e.id}
+					if pair[0] > pair[1] {
+						pair[0], pair[1] = pair[1], pair[0]
+					}
+					if !seen[pair] {
+						seen[pair] = true
+						pairs = append(pairs, pair)
+					}
+				}
+			}
+		}
+		grid[k] = append(grid[k], i)
+	}
+	return pairs
+}
@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Three pipes along the equator, roughly 111 m each, where the gap between
+// pipes 1 and 2 is about 0.1 m and the gap between 2 and 3 about 1.1 m.
+const testFeatures = `{
+  "type": "FeatureCollection",
+  "features": [
+    {"type": "Feature", "id": 1, "properties": {"lof": 0.4, "material": "PVC"},
+     "geometry": {"type": "LineString", "coordinates": [[0, 0], [0.001, 0]]}},
+    {"type": "Feature", "id": 2, "properties": {"lof": "0.7", "dn": 150},
+     "geometry": {"type": "LineString", "coordinates": [[0.0010009, 0], [0.002, 0]]}},
+    {"type": "Feature", "id": 3, "properties": {"lof": 0.1, "start_x": 5, "end_y": 5},
+     "geometry": {"type": "MultiLineString", "coordinates": [[[0.00201, 0], [0.0025, 0]], [[0.0025, 0], [0.003, 0]]]}}
+  ]
+}`
+
+func TestReadGeoJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		tol      float64
+		expected map[ID][]ID
+	}{
+		{
+			name:     "tight tolerance connects only the close pair",
+			tol:      0.5,
+			expected: map[ID][]ID{1: {2}, 2: {1}},
+		},
+		{
+			name:     "wider tolerance connects the chain",
+			tol:      2.0,
+			expected: map[ID][]ID{1: {2}, 2: {1, 3}, 3: {2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, err := ReadGeoJSON(strings.NewReader(testFeatures), GeoJSONOptions{
+				SnapTolerance: tt.tol,
+				ScoreProperty: "lof",
+			})
+			if err != nil {
+				t.Fatalf("ReadGeoJSON: %v", err)
+			}
+			if got := sortedAdjacency(net); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected edges %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	net, err := ReadGeoJSON(strings.NewReader(testFeatures), GeoJSONOptions{ScoreProperty: "lof"})
+	if err != nil {
+		t.Fatalf("ReadGeoJSON: %v", err)
+	}
+
+	// 0.001 degrees of longitude on the equator is about 111.195 m
+	if got := net.Nodes[1].Length; math.Abs(got-111.195) > 0.01 {
+		t.Errorf("expected pipe 1 to be ~111.195 m, got %.3f", got)
+	}
+	if got := net.Nodes[3].Length; math.Abs(got-110.083) > 0.01 {
+		t.Errorf("expected pipe 3 to be ~110.083 m, got %.3f", got)
+	}
+	if net.Nodes[2].Score != 0.7 {
+		t.Errorf("expected pipe 2 score 0.7, got %v", net.Nodes[2].Score)
+	}
+	if net.Nodes[1].Material != "PVC" || net.Nodes[2].Attrs["dn"] != "150" {
+		t.Errorf("expected material on the node and dn in Attrs, got %+v and %+v", net.Nodes[1], net.Nodes[2])
+	}
+	if p := net.Nodes[3]; *p.Start != (Point{X: 0.00201}) || *p.End != (Point{X: 0.003}) {
+		t.Errorf("expected pipe 3 endpoints from its geometry, not its properties, got %v and %v", p.Start, p.End)
+	}
+}
+
+func TestReadGeoJSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		msg   string
+	}{
+		{
+			name:  "not a feature collection",
+			input: `{"type": "Feature"}`,
+			msg:   "expected a FeatureCollection",
+		},
+		{
+			name:  "point geometry",
+			input: `{"type": "FeatureCollection", "features": [{"geometry": {"type": "Point", "coordinates": [0, 0]}}]}`,
+			msg:   `feature 0: unsupported geometry type "Point"`,
+		},
+		{
+			name: "duplicate id",
+			input: `{"type": "FeatureCollection", "features": [
+				{"id": 4, "geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 0]]}},
+				{"id": 4, "geometry": {"type": "LineString", "coordinates": [[1, 0], [2, 0]]}}]}`,
+			msg: "feature 1: duplicate id 4",
+		},
+		{
+			// Reported in property name order, not map order
+			name: "several invalid properties",
+			input: `{"type": "FeatureCollection", "features": [{"properties": {"install_year": "old", "diameter": "wide"},
+				"geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 0]]}}]}`,
+			msg: `feature 0: invalid diameter "wide"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadGeoJSON(strings.NewReader(tt.input), GeoJSONOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("expected error containing %q, got %v", tt.msg, err)
+			}
+		})
+	}
+}
@@ -2,15 +2,31 @@ package graph
 
 type ID int64
 
+// Point is a position in the coordinate system of the source data (X is the
+// longitude for geographic data).
+type Point struct {
+	X, Y float64
+}
+
 type Node struct {
 	ID     ID
 	Score  float64
 	Length float64
+
+	Material    string            // Pipe material, e.g. "PVC" or "cast iron"
+	Diameter    float64           // Nominal diameter in millimetres (0 if unknown)
+	InstallYear int               // Year the pipe was laid (0 if unknown)
+	Zone        string            // District or pressure zone
+	Start       *Point            // Coordinates of the first endpoint (nil if unknown)
+	End         *Point            // Coordinates of the second endpoint (nil if unknown)
+	Attrs       map[string]string // Custom attributes without a typed field
 }
 
 type Network struct {
 	Nodes map[ID]*Node
 	Edges map[ID][]ID
+
+	version uint64
 }
 
 func New() *Network {
@@ -22,9 +38,24 @@ func New() *Network {
 
 func (n *Network) AddNode(node *Node) {
 	n.Nodes[node.ID] = node
+	n.version++
 }
 
 func (n *Network) AddUndirectedEdge(from, to ID) {
 	n.Edges[from] = append(n.Edges[from], to)
 	n.Edges[to] = append(n.Edges[to], from)
+	n.version++
+}
+
+// Version is a counter that changes whenever the network is modified through
+// its methods. Results derived from the network stay valid while it is
+// unchanged.
+func (n *Network) Version() uint64 {
+	return n.version
+}
+
+// Touch marks the network as modified. Call it after changing Nodes, Edges
+// or node fields directly so that caches keyed on Version are invalidated.
+func (n *Network) Touch() {
+	n.version++
 }
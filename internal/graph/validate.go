@@ -0,0 +1,208 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// IssueKind classifies a validation finding.
+type IssueKind int
+
+const (
+	DanglingEdge    IssueKind = iota // Edge references a pipe that does not exist
+	AsymmetricEdge                   // a lists b as a neighbour more or less often than b lists a
+	DuplicateEdge                    // The same pair of pipes is connected more than once
+	SelfLoop                         // A pipe is connected to itself
+	InvalidLength                    // Length is zero, negative, NaN or infinite
+	ScoreOutOfRange                  // Score is outside [0, 1] or NaN
+	IsolatedNode                     // Pipe has no connections
+)
+
+var issueKindNames = [...]string{
+	DanglingEdge:    "dangling edge",
+	AsymmetricEdge:  "asymmetric edge",
+	DuplicateEdge:   "duplicate edge",
+	SelfLoop:        "self-loop",
+	InvalidLength:   "invalid length",
+	ScoreOutOfRange: "score out of range",
+	IsolatedNode:    "isolated node",
+}
+
+func (k IssueKind) String() string {
+	if k >= 0 && int(k) < len(issueKindNames) {
+		return issueKindNames[k]
+	}
+	return fmt.Sprintf("IssueKind(%d)", int(k))
+}
+
+// Severity tells whether an issue makes the network unusable for planning.
+type Severity int
+
+const (
+	Warning Severity = iota // Suspicious but safe to plan on
+	Error                   // Planning results would be wrong or undefined
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue is a single validation finding.
+type Issue struct {
+	Kind  IssueKind
+	Node  ID      // Pipe the issue was found on (the lower ID for edge issues)
+	Other ID      // Other endpoint for edge issues
+	Value float64 // Offending length or score
+}
+
+// Severity returns how serious the issue is. Only isolated nodes are
+// warnings; everything else is an error.
+func (i Issue) Severity() Severity {
+	if i.Kind == IsolatedNode {
+		return Warning
+	}
+	return Error
+}
+
+func (i Issue) String() string {
+	switch i.Kind {
+	case DanglingEdge, AsymmetricEdge, DuplicateEdge:
+		return fmt.Sprintf("%s: %s between %d and %d", i.Severity(), i.Kind, i.Node, i.Other)
+	case InvalidLength, ScoreOutOfRange:
+		return fmt.Sprintf("%s: %s on %d (%v)", i.Severity(), i.Kind, i.Node, i.Value)
+	}
+	return fmt.Sprintf("%s: %s on %d", i.Severity(), i.Kind, i.Node)
+}
+
+// Report collects the findings of Validate, ordered by kind and pipe ID.
+type Report struct {
+	Issues []Issue
+}
+
+// Valid reports whether the network has no error-level issues.
+func (r Report) Valid() bool {
+	for _, i := range r.Issues {
+		if i.Severity() == Error {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of issues of the given kind.
+func (r Report) Count(kind IssueKind) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns a *ValidationError if the report contains error-level issues
+// and nil otherwise.
+func (r Report) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	return &ValidationError{Report: r}
+}
+
+// ValidationError is returned when an invalid network is used where a valid
+// one is required.
+type ValidationError struct {
+	Report Report
+}
+
+func (e *ValidationError) Error() string {
+	var errs []string
+	for _, i := range e.Report.Issues {
+		if i.Severity() == Error {
+			errs = append(errs, i.String())
+		}
+	}
+	const shown = 3
+	msg := fmt.Sprintf("invalid network: %d errors: %s", len(errs), strings.Join(errs[:min(shown, len(errs))], "; "))
+	if len(errs) > shown {
+		msg += "; ..."
+	}
+	return msg
+}
+
+// Validate checks the network for structural and data problems: edges to
+// missing pipes, adjacency lists that do not mirror each other, duplicate
+// edges, self-loops, lengths that are not positive and finite, scores outside
+// [0, 1] and pipes without any connection.
+func (n *Network) Validate() Report {
+	var issues []Issue
+
+	for _, id := range sortedIDs(n.Nodes) {
+		node := n.Nodes[id]
+		if !(node.Length > 0) || math.IsInf(node.Length, 0) {
+			issues = append(issues, Issue{Kind: InvalidLength, Node: id, Value: node.Length})
+		}
+		if !(node.Score >= 0 && node.Score <= 1) {
+			issues = append(issues, Issue{Kind: ScoreOutOfRange, Node: id, Value: node.Score})
+		}
+		if len(n.Edges[id]) == 0 {
+			issues = append(issues, Issue{Kind: IsolatedNode, Node: id})
+		}
+	}
+
+	// Count every directed adjacency entry so both directions of a pair can
+	// be compared.
+	counts := make(map[[2]ID]int)
+	for from, nbrs := range n.Edges {
+		for _, to := range nbrs {
+			counts[[2]ID{from, to}]++
+		}
+	}
+
+	checked := make(map[[2]ID]bool)
+	for pair := range counts {
+		a, b := pair[0], pair[1]
+		if a > b {
+			a, b = b, a
+		}
+		if checked[[2]ID{a, b}] {
+			continue
+		}
+		checked[[2]ID{a, b}] = true
+
+		_, okA := n.Nodes[a]
+		_, okB := n.Nodes[b]
+		fwd, back := counts[[2]ID{a, b}], counts[[2]ID{b, a}]
+
+		switch {
+		case !okA || !okB:
+			issues = append(issues, Issue{Kind: DanglingEdge, Node: a, Other: b})
+		case a == b:
+			issues = append(issues, Issue{Kind: SelfLoop, Node: a, Other: b})
+		default:
+			if fwd != back {
+				issues = append(issues, Issue{Kind: AsymmetricEdge, Node: a, Other: b})
+			}
+			if fwd > 1 || back > 1 {
+				issues = append(issues, Issue{Kind: DuplicateEdge, Node: a, Other: b})
+			}
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		x, y := issues[i], issues[j]
+		if x.Kind != y.Kind {
+			return x.Kind < y.Kind
+		}
+		if x.Node != y.Node {
+			return x.Node < y.Node
+		}
+		return x.Other < y.Other
+	})
+	return Report{Issues: issues}
+}
@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	net := New()
+	net.AddNode(&Node{ID: 1, Score: 0.5, Length: 1.0})
+	net.AddNode(&Node{ID: 2, Score: 1.5, Length: 1.0})
+	net.AddNode(&Node{ID: 3, Score: 0.1, Length: 0})
+	net.AddNode(&Node{ID: 4, Score: math.NaN(), Length: math.NaN()})
+	net.AddNode(&Node{ID: 5, Score: 0.2, Length: 2.0}) // isolated
+	net.AddUndirectedEdge(1, 2)
+	net.AddUndirectedEdge(1, 2) // duplicate
+	net.AddUndirectedEdge(2, 3)
+	net.AddUndirectedEdge(3, 3)            // self-loop
+	net.AddUndirectedEdge(3, 9)            // 9 does not exist
+	net.Edges[4] = append(net.Edges[4], 1) // one-way only
+
+	report := net.Validate()
+
+	expected := []Issue{
+		{Kind: DanglingEdge, Node: 3, Other: 9},
+		{Kind: AsymmetricEdge, Node: 1, Other: 4},
+		{Kind: DuplicateEdge, Node: 1, Other: 2},
+		{Kind: SelfLoop, Node: 3, Other: 3},
+		{Kind: InvalidLength, Node: 3, Value: 0},
+		{Kind: InvalidLength, Node: 4, Value: math.NaN()},
+		{Kind: ScoreOutOfRange, Node: 2, Value: 1.5},
+		{Kind: ScoreOutOfRange, Node: 4, Value: math.NaN()},
+		{Kind: IsolatedNode, Node: 5},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %d: %v", len(expected), len(report.Issues), report.Issues)
+	}
+	for i, want := range expected {
+		got := report.Issues[i]
+		if got.Kind != want.Kind || got.Node != want.Node || got.Other != want.Other {
+			t.Errorf("issue %d: expected %v, got %v", i, want, got)
+		}
+	}
+
+	if report.Valid() {
+		t.Error("expected report to be invalid")
+	}
+	var verr *ValidationError
+	if err := report.Err(); !errors.As(err, &verr) {
+		t.Errorf("expected *ValidationError, got %v", err)
+	}
+}
+
+func TestValidateWarningsOnly(t *testing.T) {
+	net := New()
+	net.AddNode(&Node{ID: 1, Score: 0.5, Length: 1.0})
+	net.AddNode(&Node{ID: 2, Score: 0.5, Length: 1.0})
+	net.AddNode(&Node{ID: 3, Score: 0.5, Length: 1.0})
+	net.AddUndirectedEdge(1, 2)
+
+	report := net.Validate()
+	if !reflect.DeepEqual(report.Issues, []Issue{{Kind: IsolatedNode, Node: 3}}) {
+		t.Errorf("expected only pipe 3 to be reported isolated, got %v", report.Issues)
+	}
+	if !report.Valid() || report.Err() != nil {
+		t.Errorf("expected warnings not to invalidate the network, got %v", report.Err())
+	}
+}
@@ -0,0 +1,113 @@
+package neighbourhood
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// Summary aggregates the neighbourhood of one root.
+type Summary struct {
+	Root        PipeID
+	Count       int      // Number of pipes reached
+	TotalLength float64  // Sum of their lengths
+	TotalRisk   float64  // Sum of their scores
+	Pipes       []PipeID // Pipes reached in ascending ID order, only with BatchOptions.Pipes
+}
+
+// BatchOptions configures Batch.
+type BatchOptions struct {
+	Workers  int                   // Number of concurrent searches (<= 0 means runtime.GOMAXPROCS(0))
+	Pipes    bool                  // Also return the pipes reached from every root, not just the totals
+	Progress func(done, total int) // Called after every root; calls never overlap
+}
+
+// Batch computes the neighbourhood of every root within maxLen on a bounded
+// pool of workers and returns one Summary per root, in the order of roots.
+// nil roots means every pipe of the network in ascending ID order. A root
+// that does not exist gets an empty Summary.
+//
+// The network is converted to a graph.CSR once and each worker runs its own
+// CSRSearcher, so the searches measure path length like Neighbourhood.
+// If ctx is cancelled Batch stops handing out roots, waits for the running
+// searches and returns ctx.Err().
+func Batch(ctx context.Context, net *graph.Network, roots []graph.ID, maxLen float64, opts BatchOptions) ([]Summary, error) {
+	g := graph.NewCSR(net)
+	if roots == nil {
+		roots = g.IDs
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(roots))
+
+	summaries := make([]Summary, len(roots))
+	jobs := make(chan int)
+	done := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := NewCSRSearcher(g)
+			for i := range jobs {
+				summaries[i] = summarise(s, g, roots[i], maxLen, opts.Pipes)
+				done <- i
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i := range roots {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	completed := 0
+	for range done {
+		completed++
+		if opts.Progress != nil {
+			opts.Progress(completed, len(roots))
+		}
+	}
+	if err := ctx.Err(); err != nil && completed < len(roots) {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func summarise(s *CSRSearcher, g *graph.CSR, root graph.ID, maxLen float64, keepPipes bool) Summary {
+	sum := Summary{Root: root}
+	idx, ok := g.Index(root)
+	if !ok {
+		return sum
+	}
+	reached := s.Neighbourhood(idx, maxLen)
+	sum.Count = len(reached)
+	for _, i := range reached {
+		sum.TotalLength += g.Length[i]
+		sum.TotalRisk += g.Score[i]
+	}
+	if keepPipes {
+		sum.Pipes = make([]PipeID, len(reached))
+		for k, i := range reached {
+			sum.Pipes[k] = g.IDs[i]
+		}
+		sort.Slice(sum.Pipes, func(a, b int) bool { return sum.Pipes[a] < sum.Pipes[b] })
+	}
+	return sum
+}
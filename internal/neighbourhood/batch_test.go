@@ -0,0 +1,94 @@
+package neighbourhood
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestBatch(t *testing.T) {
+	net := gridNetwork(20, 7)
+	const maxLen = 200.0
+
+	var calls, last int
+	summaries, err := Batch(context.Background(), net, nil, maxLen, BatchOptions{
+		Workers: 4,
+		Pipes:   true,
+		Progress: func(done, total int) {
+			calls++
+			last = done
+			if total != len(net.Nodes) {
+				t.Errorf("expected total %d, got %d", len(net.Nodes), total)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if len(summaries) != len(net.Nodes) || calls != len(net.Nodes) || last != len(net.Nodes) {
+		t.Fatalf("expected %d summaries and progress calls, got %d and %d (last %d)",
+			len(net.Nodes), len(summaries), calls, last)
+	}
+
+	for i, sum := range summaries {
+		if sum.Root != graph.ID(i) {
+			t.Fatalf("summary %d: expected root %d, got %d", i, i, sum.Root)
+		}
+		want := Search(net, sum.Root, maxLen)
+		if !reflect.DeepEqual(sum.Pipes, want.Sorted()) {
+			t.Errorf("root %d: expected pipes %v, got %v", sum.Root, want.Sorted(), sum.Pipes)
+		}
+		var length float64
+		for _, id := range sum.Pipes {
+			length += net.Nodes[id].Length
+		}
+		if sum.Count != want.Len() || !almostEqual(sum.TotalLength, length) {
+			t.Errorf("root %d: expected %d pipes of %.3f, got %d of %.3f",
+				sum.Root, want.Len(), length, sum.Count, sum.TotalLength)
+		}
+	}
+}
+
+func TestBatchMissingRoot(t *testing.T) {
+	net := gridNetwork(3, 1)
+	summaries, err := Batch(context.Background(), net, []graph.ID{4, 99}, 1000, BatchOptions{})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if summaries[0].Count != 9 || summaries[0].Pipes != nil {
+		t.Errorf("expected 9 pipes and no pipe list for root 4, got %+v", summaries[0])
+	}
+	if !reflect.DeepEqual(summaries[1], Summary{Root: 99}) {
+		t.Errorf("expected an empty summary for a missing root, got %+v", summaries[1])
+	}
+}
+
+func TestBatchCancelled(t *testing.T) {
+	net := gridNetwork(20, 7)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var seen int
+	_, err := Batch(ctx, net, nil, 200, BatchOptions{
+		Workers: 2,
+		Progress: func(done, total int) {
+			seen = done
+			if done == 10 {
+				cancel()
+			}
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if seen >= len(net.Nodes) {
+		t.Errorf("expected the batch to stop early, it completed %d roots", seen)
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
@@ -0,0 +1,146 @@
+package neighbourhood
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// CacheStats counts how a Cache has been used.
+type CacheStats struct {
+	Hits          uint64 // Searches answered from the cache
+	Misses        uint64 // Searches that had to run
+	Uncacheable   uint64 // Searches run without caching (tracer, or Cost without CostKey)
+	Invalidations uint64 // Times the cache was emptied because the network changed
+	Entries       int    // Results currently held
+}
+
+// Cache memoises single-root searches on one network, keyed by root, budget
+// and options. It is emptied as soon as the network's Version changes, so
+// edits must go through the Network methods or be followed by
+// Network.Touch. A Cache is safe for concurrent use as long as the network
+// is not modified at the same time.
+//
+// Results are shared between callers and must not be modified.
+type Cache struct {
+	net *graph.Network
+
+	mu      sync.Mutex
+	version uint64
+	entries map[cacheKey]*Result
+	stats   CacheStats
+}
+
+type cacheKey struct {
+	root    PipeID
+	budget  float64
+	growth  Growth
+	cost    string
+	exclude string
+	include string
+}
+
+// NewCache returns an empty cache for net.
+func NewCache(net *graph.Network) *Cache {
+	return &Cache{
+		net:     net,
+		version: net.Version(),
+		entries: make(map[cacheKey]*Result),
+	}
+}
+
+// Neighbourhood is the cached form of Neighbourhood.
+func (c *Cache) Neighbourhood(root graph.ID, maxLen float64) map[PipeID]struct{} {
+	return c.Search(root, maxLen, Options{}).Pipes()
+}
+
+// Search is the cached form of SearchWith from a single root. Searches with
+// a Tracer always run, since the tracer expects to see every step.
+func (c *Cache) Search(root graph.ID, maxLen float64, opts Options) *Result {
+	seeds := []Seed{{Root: root, Budget: maxLen}}
+	if opts.Tracer != nil || (opts.Cost != nil && opts.CostKey == "") {
+		c.mu.Lock()
+		c.stats.Uncacheable++
+		c.mu.Unlock()
+		return run(c.net, seeds, opts)
+	}
+
+	key := cacheKey{
+		root:    root,
+		budget:  maxLen,
+		growth:  opts.Growth,
+		cost:    opts.CostKey,
+		exclude: setKey(opts.Exclude),
+		include: setKey(opts.Include),
+	}
+
+	c.mu.Lock()
+	c.checkVersion()
+	if res, ok := c.entries[key]; ok {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return res
+	}
+	c.stats.Misses++
+	version := c.version
+	c.mu.Unlock()
+
+	res := run(c.net, seeds, opts)
+
+	c.mu.Lock()
+	if c.version == version {
+		c.entries[key] = res
+	}
+	c.mu.Unlock()
+	return res
+}
+
+// Stats returns the counters so far.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkVersion()
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
+
+// Reset empties the cache and its counters.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version = c.net.Version()
+	c.entries = make(map[cacheKey]*Result)
+	c.stats = CacheStats{}
+}
+
+// checkVersion empties the cache if the network changed. c.mu must be held.
+func (c *Cache) checkVersion() {
+	if v := c.net.Version(); v != c.version {
+		c.version = v
+		if len(c.entries) > 0 {
+			c.entries = make(map[cacheKey]*Result)
+		}
+		c.stats.Invalidations++
+	}
+}
+
+// setKey encodes a pipe set as a comparable string.
+func setKey(set map[PipeID]struct{}) string {
+	if len(set) == 0 {
+		return ""
+	}
+	ids := make([]PipeID, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var b strings.Builder
+	for _, id := range ids {
+		fmt.Fprintf(&b, "%d,", id)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package neighbourhood
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestCache(t *testing.T) {
+	net := gridNetwork(10, 5)
+	c := NewCache(net)
+
+	first := c.Neighbourhood(0, 150)
+	if !reflect.DeepEqual(first, Neighbourhood(net, 0, 150)) {
+		t.Fatalf("expected the cached search to match Neighbourhood")
+	}
+	c.Neighbourhood(0, 150)
+	c.Neighbourhood(0, 200)                                                              // different budget
+	c.Search(0, 150, Options{Exclude: map[PipeID]struct{}{1: {}}})                       // different options
+	c.Search(0, 150, Options{Cost: LengthCost})                                          // no CostKey
+	c.Search(0, 150, Options{Cost: LengthCost, CostKey: "length"})                       // cached
+	c.Search(0, 150, Options{Cost: LengthCost, CostKey: "length"})                       // hit
+	c.Search(0, 150, Options{Tracer: TracerFunc(func(Event) {})})                        // traced
+	c.Search(0, 150, Options{Exclude: map[PipeID]struct{}{1: {}}, Growth: ShortestPath}) // hit
+
+	expected := CacheStats{Hits: 3, Misses: 4, Uncacheable: 2, Entries: 4}
+	if got := c.Stats(); got != expected {
+		t.Errorf("expected stats %+v, got %+v", expected, got)
+	}
+
+	// Changing the network through its methods or Touch empties the cache
+	net.AddNode(&graph.Node{ID: 1000, Length: 1})
+	net.AddUndirectedEdge(0, 1000)
+	if _, ok := c.Neighbourhood(0, 150)[1000]; !ok {
+		t.Errorf("expected the new pipe to be reached after invalidation")
+	}
+	net.Nodes[1000].Length = 1000
+	net.Touch()
+	if _, ok := c.Neighbourhood(0, 150)[1000]; ok {
+		t.Errorf("expected the lengthened pipe to be out of reach after Touch")
+	}
+
+	expected = CacheStats{Hits: 3, Misses: 6, Uncacheable: 2, Invalidations: 2, Entries: 1}
+	if got := c.Stats(); got != expected {
+		t.Errorf("expected stats %+v, got %+v", expected, got)
+	}
+}
@@ -0,0 +1,82 @@
+package neighbourhood
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestSearchExcludeInclude(t *testing.T) {
+	// Chain 0 - 1 - 2 - 3 - 4 - 5, all of length 1.0
+	net := graph.New()
+	for id := graph.ID(0); id < 6; id++ {
+		net.AddNode(&graph.Node{ID: id, Score: 0.5, Length: 1.0})
+	}
+	for id := graph.ID(0); id < 5; id++ {
+		net.AddUndirectedEdge(id, id+1)
+	}
+	net.Nodes[5].Length = 4.0
+
+	set := func(ids ...PipeID) map[PipeID]struct{} {
+		s := map[PipeID]struct{}{}
+		for _, id := range ids {
+			s[id] = struct{}{}
+		}
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []PipeID
+	}{
+		{
+			name:     "unconstrained",
+			expected: []PipeID{0, 1, 2},
+		},
+		{
+			// 1 blocks the only way to 2
+			name:     "excluded pipe blocks the path",
+			opts:     Options{Exclude: set(1)},
+			expected: []PipeID{0},
+		},
+		{
+			name: "excluded root",
+			opts: Options{Exclude: set(0)},
+		},
+		{
+			// 4 becomes a root with budget 3.0 and reaches 3 and 2
+			name:     "included pipe grows its own region",
+			opts:     Options{Include: set(4)},
+			expected: []PipeID{0, 1, 2, 3, 4},
+		},
+		{
+			// 5 alone costs more than the budget but is still reached
+			name:     "included pipe over budget",
+			opts:     Options{Include: set(5)},
+			expected: []PipeID{0, 1, 2, 5},
+		},
+		{
+			name:     "exclude wins over include",
+			opts:     Options{Exclude: set(4), Include: set(4)},
+			expected: []PipeID{0, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, growth := range []Growth{ShortestPath, RiskGreedy} {
+				opts := tt.opts
+				opts.Growth = growth
+				got := SearchWith(net, []Seed{{Root: 0, Budget: 3.0}}, opts).Sorted()
+				if len(got) == 0 && len(tt.expected) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(got, tt.expected) {
+					t.Errorf("growth %d: expected %v, got %v", growth, tt.expected, got)
+				}
+			}
+		})
+	}
+}
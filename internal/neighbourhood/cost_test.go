@@ -0,0 +1,68 @@
+package neighbourhood
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestSearchCost(t *testing.T) {
+	// Chain 0 - 1 - 2 - 3, all of length 10; 1 and 2 are cast iron and
+	// run under the same road as 0
+	net := graph.New()
+	net.AddNode(&graph.Node{ID: 0, Length: 10, Material: "PVC", Attrs: map[string]string{"road": "A"}})
+	net.AddNode(&graph.Node{ID: 1, Length: 10, Material: "CI", Attrs: map[string]string{"road": "A"}})
+	net.AddNode(&graph.Node{ID: 2, Length: 10, Material: "CI", Attrs: map[string]string{"road": "A"}})
+	net.AddNode(&graph.Node{ID: 3, Length: 10, Material: "PVC", Attrs: map[string]string{"road": "B"}})
+	net.AddUndirectedEdge(0, 1)
+	net.AddUndirectedEdge(1, 2)
+	net.AddUndirectedEdge(2, 3)
+
+	euroPerMetre := map[string]float64{"PVC": 100, "CI": 250}
+	euros := NodeCost(func(n *graph.Node) float64 { return n.Length * euroPerMetre[n.Material] })
+
+	// Half the length when the trench is shared with the previous pipe
+	sameRoad := func(from, to *graph.Node) float64 {
+		if from != nil && from.Attrs["road"] == to.Attrs["road"] {
+			return to.Length / 2
+		}
+		return to.Length
+	}
+
+	tests := []struct {
+		name     string
+		cost     CostFunc
+		budget   float64
+		expected map[PipeID]float64
+	}{
+		{
+			name:     "default is length",
+			budget:   30,
+			expected: map[PipeID]float64{0: 10, 1: 20, 2: 30},
+		},
+		{
+			// 0: 1000, 1: 1000+2500, 2: 3500+2500
+			name:     "per-node euros",
+			cost:     euros,
+			budget:   6000,
+			expected: map[PipeID]float64{0: 1000, 1: 3500, 2: 6000},
+		},
+		{
+			// 0: 10, 1: 10+5, 2: 15+5, 3: 20+10
+			name:     "per-edge road discount",
+			cost:     sameRoad,
+			budget:   30,
+			expected: map[PipeID]float64{0: 10, 1: 15, 2: 20, 3: 30},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := SearchWith(net, []Seed{{Root: 0, Budget: tt.budget}}, Options{Cost: tt.cost})
+			if !reflect.DeepEqual(res.Dist, tt.expected) {
+				t.Errorf("expected costs %v, got %v", tt.expected, res.Dist)
+			}
+		})
+	}
+}
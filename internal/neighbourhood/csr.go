@@ -0,0 +1,146 @@
+package neighbourhood
+
+import "github.com/thanos-fil/planner-demo-go/internal/graph"
+
+// CSRSearcher runs neighbourhood searches on a graph.CSR. It keeps its
+// distance arrays and heap between calls, so repeated searches only pay for
+// the pipes they touch. A CSRSearcher is not safe for concurrent use; create
+// one per goroutine.
+type CSRSearcher struct {
+	g     *graph.CSR
+	dist  []float64
+	stamp []uint32 // dist[i] is valid only when stamp[i] == epoch
+	epoch uint32
+	pq    csrHeap
+}
+
+// NewCSRSearcher returns a searcher for g.
+func NewCSRSearcher(g *graph.CSR) *CSRSearcher {
+	return &CSRSearcher{
+		g:     g,
+		dist:  make([]float64, g.Len()),
+		stamp: make([]uint32, g.Len()),
+	}
+}
+
+// Neighbourhood returns the dense indices of all pipes whose cumulative
+// length from root is at most maxLen, in the order they were reached. It
+// selects the same pipes as the map-based Neighbourhood. The returned slice
+// is newly allocated.
+func (s *CSRSearcher) Neighbourhood(root int32, maxLen float64) []int32 {
+	s.epoch++
+	if s.epoch == 0 {
+		// Wrapped around: clear the stamps so stale entries are not reused
+		for i := range s.stamp {
+			s.stamp[i] = 0
+		}
+		s.epoch = 1
+	}
+
+	g := s.g
+	var visited []int32
+	if root < 0 || int(root) >= g.Len() || g.Length[root] > maxLen {
+		return visited
+	}
+
+	s.pq = s.pq[:0]
+	s.setDist(root, g.Length[root])
+	s.pq.push(csrItem{idx: root, cumLen: g.Length[root]})
+
+	for len(s.pq) > 0 {
+		it := s.pq.pop()
+		if it.cumLen > s.dist[it.idx] {
+			continue // stale entry, a shorter path was found later
+		}
+		visited = append(visited, it.idx)
+
+		for _, nbr := range g.Neighbours(it.idx) {
+			nextLen := it.cumLen + g.Length[nbr]
+			if nextLen > maxLen {
+				continue // pruned by budget
+			}
+			if s.stamp[nbr] != s.epoch || nextLen < s.dist[nbr] {
+				s.setDist(nbr, nextLen)
+				s.pq.push(csrItem{idx: nbr, cumLen: nextLen})
+			}
+		}
+	}
+	return visited
+}
+
+func (s *CSRSearcher) setDist(i int32, d float64) {
+	s.dist[i] = d
+	s.stamp[i] = s.epoch
+}
+
+// NeighbourhoodCSR is Neighbourhood on the CSR form of a network, returning
+// the result keyed by pipe ID. Use a CSRSearcher directly to avoid the map
+// and buffer allocations when running many searches.
+func NeighbourhoodCSR(g *graph.CSR, root graph.ID, maxLen float64) map[PipeID]struct{} {
+	result := map[PipeID]struct{}{}
+	idx, ok := g.Index(root)
+	if !ok {
+		return result
+	}
+	for _, i := range NewCSRSearcher(g).Neighbourhood(idx, maxLen) {
+		result[g.IDs[i]] = struct{}{}
+	}
+	return result
+}
+
+type csrItem struct {
+	idx    int32
+	cumLen float64
+}
+
+// csrHeap is a binary min-heap on cumLen, then index (which follows pipe
+// ID), specialised to avoid the interface calls of container/heap.
+type csrHeap []csrItem
+
+func (a csrItem) less(b csrItem) bool {
+	if a.cumLen != b.cumLen {
+		return a.cumLen < b.cumLen
+	}
+	return a.idx < b.idx
+}
+
+func (h *csrHeap) push(it csrItem) {
+	*h = append(*h, it)
+	q := *h
+	i := len(q) - 1
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q[i].less(q[parent]) {
+			break
+		}
+		q[parent], q[i] = q[i], q[parent]
+		i = parent
+	}
+}
+
+func (h *csrHeap) pop() csrItem {
+	q := *h
+	top := q[0]
+	last := len(q) - 1
+	q[0] = q[last]
+	q = q[:last]
+
+	i := 0
+	for {
+		smallest := i
+		l, r := 2*i+1, 2*i+2
+		if l < len(q) && q[l].less(q[smallest]) {
+			smallest = l
+		}
+		if r < len(q) && q[r].less(q[smallest]) {
+			smallest = r
+		}
+		if smallest == i {
+			break
+		}
+		q[i], q[smallest] = q[smallest], q[i]
+		i = smallest
+	}
+	*h = q
+	return top
+}
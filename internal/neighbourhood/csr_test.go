@@ -0,0 +1,101 @@
+package neighbourhood
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// gridNetwork builds a size x size grid of pipes with lengths between 10 and
+// 100, plus a few random cross-links, from a fixed seed.
+func gridNetwork(size int, seed int64) *graph.Network {
+	rng := rand.New(rand.NewSource(seed))
+	net := graph.New()
+	n := size * size
+	for i := 0; i < n; i++ {
+		net.AddNode(&graph.Node{ID: graph.ID(i), Score: rng.Float64(), Length: 10 + rng.Float64()*90})
+	}
+	for i := 0; i < n; i++ {
+		if (i+1)%size != 0 {
+			net.AddUndirectedEdge(graph.ID(i), graph.ID(i+1))
+		}
+		if i+size < n {
+			net.AddUndirectedEdge(graph.ID(i), graph.ID(i+size))
+		}
+		if rng.Float64() < 0.05 {
+			net.AddUndirectedEdge(graph.ID(i), graph.ID(rng.Intn(n)))
+		}
+	}
+	return net
+}
+
+func TestNeighbourhoodCSRMatchesMap(t *testing.T) {
+	net := gridNetwork(30, 1)
+	g := graph.NewCSR(net)
+	s := NewCSRSearcher(g)
+
+	for _, root := range []graph.ID{0, 17, 450, 899} {
+		for _, maxLen := range []float64{5, 50, 250, 1000} {
+			want := neighbourhood(net, root, maxLen)
+
+			if got := NeighbourhoodCSR(g, root, maxLen); !reflect.DeepEqual(got, want) {
+				t.Errorf("root %d, maxLen %.0f: expected %d pipes, got %d", root, maxLen, len(want), len(got))
+			}
+
+			// The reusable searcher must give the same answer on every call
+			idx, _ := g.Index(root)
+			got := map[PipeID]struct{}{}
+			for _, i := range s.Neighbourhood(idx, maxLen) {
+				got[g.IDs[i]] = struct{}{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("searcher root %d, maxLen %.0f: expected %d pipes, got %d", root, maxLen, len(want), len(got))
+			}
+		}
+	}
+}
+
+var (
+	benchOnce sync.Once
+	benchNet  *graph.Network
+	benchCSR  *graph.CSR
+)
+
+// benchNetwork returns a 100k pipe grid shared by the benchmarks.
+func benchNetwork() (*graph.Network, *graph.CSR) {
+	benchOnce.Do(func() {
+		benchNet = gridNetwork(317, 42)
+		benchCSR = graph.NewCSR(benchNet)
+	})
+	return benchNet, benchCSR
+}
+
+const benchBudget = 400.0
+
+func BenchmarkNeighbourhoodMap(b *testing.B) {
+	net, _ := benchNetwork()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Neighbourhood(net, graph.ID(i%len(net.Nodes)), benchBudget)
+	}
+}
+
+func BenchmarkNeighbourhoodCSR(b *testing.B) {
+	_, g := benchNetwork()
+	s := NewCSRSearcher(g)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Neighbourhood(int32(i%g.Len()), benchBudget)
+	}
+}
+
+func BenchmarkNewCSR(b *testing.B) {
+	net, _ := benchNetwork()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.NewCSR(net)
+	}
+}
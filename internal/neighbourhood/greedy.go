@@ -0,0 +1,101 @@
+package neighbourhood
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// greedy implements RiskGreedy growth. Seeds grow one after the other, each
+// into its own cluster, and a pipe taken by an earlier seed is not taken
+// again. The frontier pipe with the most risk per unit of cost is added
+// next; a pipe that no longer fits in what is left of the budget is dropped
+// and the next best tried. Dist records the cluster's total cost once the
+// pipe was added and Pred the cluster pipe it was attached to.
+//
+// Queue entries carry the cost of the single step in cumLen, since the
+// cluster total is only known when a pipe is added.
+func greedy(net *graph.Network, seeds []Seed, opts Options, g *guard) (*Result, error) {
+	res := newResult()
+	cost := opts.cost()
+	trace := func(e Event) {
+		if opts.Tracer != nil {
+			opts.Tracer.Trace(e)
+		}
+	}
+
+	for _, s := range seeds {
+		root, ok := net.Nodes[s.Root]
+		if !ok || res.Contains(s.Root) {
+			continue
+		}
+
+		total := 0.0
+		best := map[PipeID]float64{} // cheapest step pushed per pipe
+		pq := make(PriorityQueue, 0, 16)
+		push := func(to *graph.Node, from PipeID, step float64) error {
+			e := Event{Kind: Push, Pipe: to.ID, From: from, Source: s.Root, CumLen: total + step, Budget: s.Budget}
+			if prev, ok := best[to.ID]; ok {
+				if step >= prev {
+					e.Kind, e.Prev = Skip, total+prev
+					trace(e)
+					return nil
+				}
+				e.Kind, e.Prev = Relax, total+prev
+			}
+			if err := g.queue(pq.Len()); err != nil {
+				return err
+			}
+			best[to.ID] = step
+			heap.Push(&pq, &pqItem{id: to.ID, cumLen: step, key: -riskPerCost(to.Score, step), src: s.Root, from: from, budget: s.Budget})
+			trace(e)
+			return nil
+		}
+
+		if err := push(root, s.Root, cost(nil, root)); err != nil {
+			return res, err
+		}
+		for pq.Len() > 0 {
+			it := heap.Pop(&pq).(*pqItem)
+			e := Event{Pipe: it.id, From: it.from, Source: s.Root, CumLen: total + it.cumLen, Budget: s.Budget}
+			if res.Contains(it.id) || it.cumLen > best[it.id] {
+				e.Kind, e.Prev, e.Dequeued = Skip, total+best[it.id], true
+				trace(e)
+				continue // stale entry, a cheaper step onto it was queued later
+			}
+			if total+it.cumLen > s.Budget {
+				e.Kind = Prune
+				trace(e)
+				continue // does not fit in what is left, try the next best
+			}
+			if err := g.visit(res.Len()); err != nil {
+				return res, err
+			}
+			total += it.cumLen
+			res.add(it.id, total, s.Root, it.from)
+			e.Kind = Pop
+			trace(e)
+
+			node := net.Nodes[it.id]
+			for _, nbr := range net.Edges[it.id] {
+				nbrNode, ok := net.Nodes[nbr]
+				if !ok || res.Contains(nbr) || opts.excluded(nbr) {
+					continue // dangling edge, already in a cluster or blocked
+				}
+				if err := push(nbrNode, it.id, cost(node, nbrNode)); err != nil {
+					return res, err
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// riskPerCost is the greedy priority of a pipe. Free pipes come first.
+func riskPerCost(score, cost float64) float64 {
+	if cost <= 0 {
+		return math.Inf(1)
+	}
+	return score / cost
+}
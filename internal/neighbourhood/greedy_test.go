@@ -0,0 +1,91 @@
+package neighbourhood
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestSearchRiskGreedy(t *testing.T) {
+	// Root 0 with three branches:
+	//   0 - 1             1 has no risk
+	//   0 - 2 - 3         2 is long and risky, 3 is short and riskier per metre
+	//   0 - 4             4 is short with some risk
+	net := graph.New()
+	net.AddNode(&graph.Node{ID: 0, Score: 0.5, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 1, Score: 0.0, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 2, Score: 0.9, Length: 3.0})
+	net.AddNode(&graph.Node{ID: 3, Score: 0.8, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 4, Score: 0.2, Length: 1.0})
+	net.AddUndirectedEdge(0, 1)
+	net.AddUndirectedEdge(0, 2)
+	net.AddUndirectedEdge(2, 3)
+	net.AddUndirectedEdge(0, 4)
+
+	tests := []struct {
+		name     string
+		budget   float64
+		expected map[PipeID]float64
+	}{
+		{
+			// 2 (0.3/m) beats 4 (0.2/m) and 1, then 3 (0.8/m) becomes reachable
+			name:     "risk per metre first",
+			budget:   6.0,
+			expected: map[PipeID]float64{0: 1.0, 2: 4.0, 3: 5.0, 4: 6.0},
+		},
+		{
+			// 2 does not fit in the 2.5 left after 0, 4 does
+			name:     "skips pipes that do not fit",
+			budget:   3.5,
+			expected: map[PipeID]float64{0: 1.0, 4: 2.0, 1: 3.0},
+		},
+		{
+			name:     "everything",
+			budget:   10.0,
+			expected: map[PipeID]float64{0: 1.0, 2: 4.0, 3: 5.0, 4: 6.0, 1: 7.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := SearchWith(net, []Seed{{Root: 0, Budget: tt.budget}}, Options{Growth: RiskGreedy})
+			if !reflect.DeepEqual(res.Dist, tt.expected) {
+				t.Errorf("expected cluster totals %v, got %v", tt.expected, res.Dist)
+			}
+		})
+	}
+
+	// Shortest-path growth spends the same budget on the nearest pipes
+	res := SearchWith(net, []Seed{{Root: 0, Budget: 2.0}}, Options{})
+	if got, want := res.Sorted(), []PipeID{0, 1, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected shortest-path pipes %v, got %v", want, got)
+	}
+
+	// The cluster stays connected: 3 hangs off 2
+	res = SearchWith(net, []Seed{{Root: 0, Budget: 6.0}}, Options{Growth: RiskGreedy})
+	if got, want := res.Path(3), []PipeID{0, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected path %v, got %v", want, got)
+	}
+}
+
+func TestSearchRiskGreedyMultiSeed(t *testing.T) {
+	// Chain 0 - 1 - 2 - 3, all of length 1.0
+	net := graph.New()
+	for id := graph.ID(0); id < 4; id++ {
+		net.AddNode(&graph.Node{ID: id, Score: 0.5, Length: 1.0})
+	}
+	for id := graph.ID(0); id < 3; id++ {
+		net.AddUndirectedEdge(id, id+1)
+	}
+
+	// 0 takes 1 and 2, so 3 grows on its own
+	res := SearchWith(net, []Seed{{Root: 0, Budget: 3.0}, {Root: 3, Budget: 3.0}}, Options{Growth: RiskGreedy})
+	expected := map[PipeID]PipeID{0: 0, 1: 0, 2: 0, 3: 3}
+	if !reflect.DeepEqual(res.Source, expected) {
+		t.Errorf("expected sources %v, got %v", expected, res.Source)
+	}
+	if res.Dist[3] != 1.0 {
+		t.Errorf("expected second cluster total 1.0, got %v", res.Dist[3])
+	}
+}
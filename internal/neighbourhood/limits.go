@@ -0,0 +1,95 @@
+package neighbourhood
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// Limits caps the resources a single search may use. Zero fields mean no
+// limit.
+type Limits struct {
+	MaxVisited int // Most pipes the search may reach
+	MaxQueue   int // Most entries the priority queue may hold
+}
+
+// Limit names the limit a search ran into.
+type Limit int
+
+const (
+	VisitedLimit Limit = iota // Limits.MaxVisited
+	QueueLimit                // Limits.MaxQueue
+)
+
+func (l Limit) String() string {
+	switch l {
+	case VisitedLimit:
+		return "visited pipes"
+	case QueueLimit:
+		return "queue size"
+	}
+	return fmt.Sprintf("Limit(%d)", int(l))
+}
+
+// LimitError is returned by SearchContext when a search exceeds one of its
+// Limits.
+type LimitError struct {
+	Limit Limit
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("neighbourhood: search exceeded limit of %d %s", e.Max, e.Limit)
+}
+
+// SearchContext is SearchWith that stops when ctx is cancelled or its
+// deadline passes, or when the search exceeds limits. It then returns the
+// pipes reached so far together with ctx.Err() or a *LimitError.
+func SearchContext(ctx context.Context, net *graph.Network, seeds []Seed, opts Options, limits Limits) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return newResult(), err
+	}
+	return search(net, seeds, opts, &guard{ctx: ctx, limits: limits})
+}
+
+// checkEvery is how many pipes a search reaches between looks at its
+// context, which keeps the cost of polling ctx.Done negligible.
+const checkEvery = 64
+
+// guard enforces the context and limits of a search. A nil guard allows
+// everything.
+type guard struct {
+	ctx    context.Context
+	limits Limits
+	visits int
+}
+
+// visit is called before a search reaches another pipe, with the number of
+// pipes it has reached so far.
+func (g *guard) visit(reached int) error {
+	if g == nil {
+		return nil
+	}
+	if g.limits.MaxVisited > 0 && reached >= g.limits.MaxVisited {
+		return &LimitError{Limit: VisitedLimit, Max: g.limits.MaxVisited}
+	}
+	g.visits++
+	if g.visits%checkEvery == 0 {
+		select {
+		case <-g.ctx.Done():
+			return g.ctx.Err()
+		default:
+		}
+	}
+	return nil
+}
+
+// queue is called before a search queues a path, with the current queue
+// length.
+func (g *guard) queue(queued int) error {
+	if g == nil || g.limits.MaxQueue <= 0 || queued < g.limits.MaxQueue {
+		return nil
+	}
+	return &LimitError{Limit: QueueLimit, Max: g.limits.MaxQueue}
+}
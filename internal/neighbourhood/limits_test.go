@@ -0,0 +1,114 @@
+package neighbourhood
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSearchContextLimits(t *testing.T) {
+	net := gridNetwork(20, 3)
+	seeds := []Seed{{Root: 0, Budget: 1e9}} // reaches all 400 pipes
+
+	tests := []struct {
+		name    string
+		limits  Limits
+		limit   Limit
+		reached int
+	}{
+		{
+			name:    "no limits",
+			reached: 400,
+		},
+		{
+			name:    "limits not hit",
+			limits:  Limits{MaxVisited: 400, MaxQueue: 1000},
+			reached: 400,
+		},
+		{
+			name:    "visited",
+			limits:  Limits{MaxVisited: 25},
+			limit:   VisitedLimit,
+			reached: 25,
+		},
+		{
+			name:   "queue",
+			limits: Limits{MaxQueue: 5},
+			limit:  QueueLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := SearchContext(context.Background(), net, seeds, Options{}, tt.limits)
+
+			var lerr *LimitError
+			if tt.reached == 400 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			} else if !errors.As(err, &lerr) || lerr.Limit != tt.limit {
+				t.Fatalf("expected %v limit error, got %v", tt.limit, err)
+			}
+			if tt.reached > 0 && res.Len() != tt.reached {
+				t.Errorf("expected %d pipes reached, got %d", tt.reached, res.Len())
+			}
+		})
+	}
+}
+
+func TestSearchContextQueueNeverExceedsLimit(t *testing.T) {
+	net := gridNetwork(20, 3)
+	seeds := []Seed{{Root: 0, Budget: 1e9}}
+
+	for _, growth := range []Growth{ShortestPath, RiskGreedy} {
+		// Rebuild the queue length from the events
+		queued, most := 0, 0
+		tracer := TracerFunc(func(e Event) {
+			switch {
+			case e.Kind == Push || e.Kind == Relax:
+				queued++
+				most = max(most, queued)
+			case e.Kind == Pop || e.Kind == Prune || e.Dequeued:
+				queued--
+			}
+		})
+		_, err := SearchContext(context.Background(), net, seeds, Options{Tracer: tracer, Growth: growth}, Limits{MaxQueue: 5})
+		var lerr *LimitError
+		if !errors.As(err, &lerr) || lerr.Limit != QueueLimit {
+			t.Fatalf("growth %d: expected a queue limit error, got %v", growth, err)
+		}
+		if most != 5 {
+			t.Errorf("growth %d: expected the queue to fill up to 5 entries, got %d", growth, most)
+		}
+	}
+}
+
+func TestSearchContextCancelled(t *testing.T) {
+	net := gridNetwork(20, 3)
+	seeds := []Seed{{Root: 0, Budget: 1e9}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SearchContext(ctx, net, seeds, Options{}, Limits{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// Cancelled part way through, from the tracer
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	tracer := TracerFunc(func(e Event) {
+		if e.Kind == Pop && e.Pipe == 21 {
+			cancel()
+		}
+	})
+	for _, growth := range []Growth{ShortestPath, RiskGreedy} {
+		res, err := SearchContext(ctx, net, seeds, Options{Tracer: tracer, Growth: growth}, Limits{})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("%v: expected context.Canceled, got %v", growth, err)
+		}
+		if res.Len() >= len(net.Nodes) {
+			t.Errorf("%v: expected the search to stop early, it reached %d pipes", growth, res.Len())
+		}
+	}
+}
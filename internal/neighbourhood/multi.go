@@ -0,0 +1,34 @@
+package neighbourhood
+
+import "github.com/thanos-fil/planner-demo-go/internal/graph"
+
+// Seed is a root of a multi-source search together with its own budget.
+type Seed struct {
+	Root   PipeID
+	Budget float64
+}
+
+// MultiNeighbourhood grows one region from several roots sharing the same
+// budget. The result maps every pipe within maxLen of any root to the root
+// it is nearest to.
+func MultiNeighbourhood(net *graph.Network, roots []graph.ID, maxLen float64) map[PipeID]PipeID {
+	seeds := make([]Seed, len(roots))
+	for i, r := range roots {
+		seeds[i] = Seed{Root: r, Budget: maxLen}
+	}
+	return run(net, seeds, Options{}).Source
+}
+
+// MultiNeighbourhoodBudgets grows one region from several roots, each with
+// its own budget. The result maps every pipe reached to the root it was
+// reached from: when several roots reach a pipe, the one with the most
+// budget left at that pipe.
+func MultiNeighbourhoodBudgets(net *graph.Network, seeds []Seed) map[PipeID]PipeID {
+	return run(net, seeds, Options{}).Source
+}
+
+// SearchMulti is MultiNeighbourhoodBudgets returning the full shortest-path
+// forest, with one tree per root.
+func SearchMulti(net *graph.Network, seeds []Seed) *Result {
+	return run(net, seeds, Options{})
+}
@@ -0,0 +1,78 @@
+package neighbourhood
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestMultiNeighbourhood(t *testing.T) {
+	// Chain: 0 - 1 - 2 - 3 - 4 with lengths 1.0, 1.0, 2.0, 1.5, 1.0
+	net := graph.New()
+	for i, l := range []float64{1.0, 1.0, 2.0, 1.5, 1.0} {
+		net.AddNode(&graph.Node{ID: graph.ID(i), Length: l})
+	}
+	net.AddUndirectedEdge(0, 1)
+	net.AddUndirectedEdge(1, 2)
+	net.AddUndirectedEdge(2, 3)
+	net.AddUndirectedEdge(3, 4)
+
+	t.Run("shared budget", func(t *testing.T) {
+		result := MultiNeighbourhood(net, []graph.ID{0, 4}, 3.0)
+
+		// From 0: 0 (1.0), 1 (2.0); node 2 would be 4.0
+		// From 4: 4 (1.0), 3 (2.5); node 2 would be 4.5
+		expected := map[PipeID]PipeID{0: 0, 1: 0, 3: 4, 4: 4}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+	})
+
+	t.Run("nearest seed wins", func(t *testing.T) {
+		result := MultiNeighbourhood(net, []graph.ID{0, 4}, 10.0)
+
+		// Node 2: 4.0 from 0 and 4.5 from 4
+		expected := map[PipeID]PipeID{0: 0, 1: 0, 2: 0, 3: 4, 4: 4}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+	})
+
+	t.Run("budget per seed", func(t *testing.T) {
+		result := MultiNeighbourhoodBudgets(net, []Seed{{Root: 0, Budget: 5.0}, {Root: 4, Budget: 1.0}})
+
+		// From 0: 0 (1.0), 1 (2.0), 2 (4.0); node 3 would be 5.5
+		// From 4: only 4 itself, node 3 would be 2.5
+		expected := map[PipeID]PipeID{0: 0, 1: 0, 2: 0, 4: 4}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+	})
+
+	t.Run("larger budget is not shadowed by a nearer seed", func(t *testing.T) {
+		// Seed 3 reaches 3 itself with 1.0 budget left, seed 0 reaches it
+		// with 4.5 left and must keep going through it to 4.
+		result := MultiNeighbourhoodBudgets(net, []Seed{{Root: 0, Budget: 10.0}, {Root: 3, Budget: 2.5}})
+
+		expected := map[PipeID]PipeID{0: 0, 1: 0, 2: 0, 3: 0, 4: 0}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+	})
+
+	t.Run("single root matches Neighbourhood", func(t *testing.T) {
+		for _, maxLen := range []float64{0.5, 1.0, 3.0, 5.5, 10.0} {
+			single := neighbourhood(net, 2, maxLen)
+			multi := MultiNeighbourhood(net, []graph.ID{2}, maxLen)
+			if len(single) != len(multi) {
+				t.Errorf("maxLen %.1f: expected %d nodes, got %d", maxLen, len(single), len(multi))
+			}
+			for id := range single {
+				if multi[id] != 2 {
+					t.Errorf("maxLen %.1f: expected node %d reached from 2", maxLen, id)
+				}
+			}
+		}
+	})
+}
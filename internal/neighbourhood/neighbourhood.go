@@ -2,6 +2,8 @@ package neighbourhood
 
 import (
 	"container/heap"
+	"math"
+	"sort"
 
 	"github.com/thanos-fil/planner-demo-go/internal/graph"
 )
@@ -14,8 +16,13 @@ type PriorityQueue []*pqItem
 
 func (pq PriorityQueue) Len() int { return len(pq) }
 
+// Less orders by key, then by pipe ID, so equal keys always pop in the same
+// order.
 func (pq PriorityQueue) Less(i, j int) bool {
-	return pq[i].cumLen < pq[j].cumLen
+	if pq[i].key != pq[j].key {
+		return pq[i].key < pq[j].key
+	}
+	return pq[i].id < pq[j].id
 }
 
 func (pq PriorityQueue) Swap(i, j int) {
@@ -44,35 +51,158 @@ func (pq *PriorityQueue) Pop() interface{} {
 type pqItem struct {
 	id     PipeID
 	cumLen float64
+	key    float64 // priority, smallest first
+	src    PipeID  // root the path started from
+	from   PipeID  // previous pipe on the path (id itself for a root)
+	budget float64 // budget of src
 	index  int
 }
 
-func neighbourhood(net *graph.Network, root graph.ID, maxLen float64) map[PipeID]struct{} {
-	inQueue := map[PipeID]float64{root: net.Nodes[root].Length}
-	visited := map[PipeID]struct{}{}
+// search grows a region from one or more seeds and records, for every pipe
+// reached, its cumulative length, predecessor and root.
+//
+// A pipe is reached when its cumulative length from some seed is within that
+// seed's budget. When several seeds reach it, the one leaving the most budget
+// unused wins; with equal budgets that is simply the nearest seed. The queue
+// is ordered by cumLen minus the seed's budget surplus over the smallest
+// budget (i.e. by remaining budget), which keeps this exact even when
+// budgets differ: the label with the most budget left can always reach at
+// least as far as any other.
+//
+// A non-nil guard is checked as the search goes; when it fails the pipes
+// reached so far are returned with its error.
+func search(net *graph.Network, seeds []Seed, opts Options, g *guard) (*Result, error) {
+	seeds = opts.roots(net, seeds)
+	if opts.Growth == RiskGreedy {
+		return greedy(net, seeds, opts, g)
+	}
+	res := newResult()
+	if len(seeds) == 0 {
+		return res, nil
+	}
+	minBudget := seeds[0].Budget
+	for _, s := range seeds[1:] {
+		minBudget = math.Min(minBudget, s.Budget)
+	}
 
+	cost := opts.cost()
+
+	type label struct{ key, cumLen float64 }
+	best := map[PipeID]label{} // best path pushed per pipe
 	pq := make(PriorityQueue, 0, 16)
-	heap.Push(&pq, &pqItem{id: root, cumLen: inQueue[root]})
+	push := func(id PipeID, cumLen float64, src, from PipeID, budget float64) error {
+		key := cumLen - (budget - minBudget)
+		prev, queued := best[id]
+		if queued && key >= prev.key {
+			// already have a path with at least as much budget left
+			if opts.Tracer != nil {
+				opts.Tracer.Trace(Event{Kind: Skip, Pipe: id, From: from, Source: src, CumLen: cumLen, Budget: budget, Prev: prev.cumLen})
+			}
+			return nil
+		}
+		if err := g.queue(pq.Len()); err != nil {
+			return err
+		}
+		best[id] = label{key, cumLen}
+		heap.Push(&pq, &pqItem{id: id, cumLen: cumLen, key: key, src: src, from: from, budget: budget})
+		if opts.Tracer != nil {
+			e := Event{Kind: Push, Pipe: id, From: from, Source: src, CumLen: cumLen, Budget: budget}
+			if queued {
+				e.Kind, e.Prev = Relax, prev.cumLen
+			}
+			opts.Tracer.Trace(e)
+		}
+		return nil
+	}
+
+	for _, s := range seeds {
+		if node, ok := net.Nodes[s.Root]; ok {
+			if err := push(s.Root, cost(nil, node), s.Root, s.Root, s.Budget); err != nil {
+				return res, err
+			}
+		}
+	}
 
 	for pq.Len() > 0 {
 		it := heap.Pop(&pq).(*pqItem)
-		if it.cumLen > maxLen {
+		if it.cumLen > it.budget {
+			if opts.Tracer != nil {
+				opts.Tracer.Trace(Event{Kind: Prune, Pipe: it.id, From: it.from, Source: it.src, CumLen: it.cumLen, Budget: it.budget})
+			}
 			continue // pruned by budget
 		}
-		visited[it.id] = struct{}{}
+		if res.Contains(it.id) {
+			if opts.Tracer != nil {
+				opts.Tracer.Trace(Event{Kind: Skip, Pipe: it.id, From: it.from, Source: it.src, CumLen: it.cumLen, Budget: it.budget, Prev: res.Dist[it.id], Dequeued: true})
+			}
+			continue // stale entry, reached earlier with more budget left
+		}
+		if err := g.visit(res.Len()); err != nil {
+			return res, err
+		}
+		res.add(it.id, it.cumLen, it.src, it.from)
+		if opts.Tracer != nil {
+			opts.Tracer.Trace(Event{Kind: Pop, Pipe: it.id, From: it.from, Source: it.src, CumLen: it.cumLen, Budget: it.budget})
+		}
 
+		node := net.Nodes[it.id]
 		for _, nbr := range net.Edges[it.id] {
-			nextLen := it.cumLen + net.Nodes[nbr].Length
-			if prev, ok := inQueue[nbr]; !ok || nextLen < prev {
-				inQueue[nbr] = nextLen
-				heap.Push(&pq, &pqItem{id: nbr, cumLen: nextLen})
+			nbrNode, ok := net.Nodes[nbr]
+			if !ok {
+				continue // dangling edge, see graph.Network.Validate
+			}
+			if opts.excluded(nbr) {
+				continue // blocked
+			}
+			if err := push(nbr, it.cumLen+cost(node, nbrNode), it.src, it.id, it.budget); err != nil {
+				return res, err
 			}
 		}
 	}
-	return visited
+	return res, nil
+}
+
+// run is search without a guard, which cannot fail.
+func run(net *graph.Network, seeds []Seed, opts Options) *Result {
+	res, _ := search(net, seeds, opts, nil)
+	return res
+}
+
+func neighbourhood(net *graph.Network, root graph.ID, maxLen float64) map[PipeID]struct{} {
+	return run(net, []Seed{{Root: root, Budget: maxLen}}, Options{}).Pipes()
 }
 
 // Neighbourhood is the exported version of the neighbourhood function
 func Neighbourhood(net *graph.Network, root graph.ID, maxLen float64) map[PipeID]struct{} {
 	return neighbourhood(net, root, maxLen)
 }
+
+// NeighbourhoodSorted is Neighbourhood returning the pipes in ascending ID
+// order.
+func NeighbourhoodSorted(net *graph.Network, root graph.ID, maxLen float64) []PipeID {
+	return SortedIDs(neighbourhood(net, root, maxLen))
+}
+
+// SortedIDs returns the pipes of a set in ascending ID order, for iterating
+// a neighbourhood reproducibly.
+func SortedIDs(set map[PipeID]struct{}) []PipeID {
+	ids := make([]PipeID, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Search is Neighbourhood returning the full shortest-path tree: the
+// cumulative length of every pipe reached and its predecessor on the
+// shortest path from root.
+func Search(net *graph.Network, root graph.ID, maxLen float64) *Result {
+	return run(net, []Seed{{Root: root, Budget: maxLen}}, Options{})
+}
+
+// SearchWith runs the search from one or more seeds with the given options.
+// Search and SearchMulti are SearchWith with the zero Options.
+func SearchWith(net *graph.Network, seeds []Seed, opts Options) *Result {
+	return run(net, seeds, opts)
+}
@@ -33,6 +33,11 @@ func TestNeighbourhoodDebug(t *testing.T) {
 	t.Run("debug from node 2 with maxLen 3.0", func(t *testing.T) {
 		result := neighbourhood(net, 2, 3.0)
 
+		// Log every step the search takes (visible with -v)
+		SearchWith(net, []Seed{{Root: 2, Budget: 3.0}}, Options{
+			Tracer: TracerFunc(func(e Event) { t.Log(e) }),
+		})
+
 		fmt.Printf("Network structure:\n")
 		for id, node := range net.Nodes {
 			fmt.Printf("Node %d: Length=%.1f, Neighbors=%v\n", id, node.Length, net.Edges[id])
@@ -1,8 +1,9 @@
-package neighbourhood
+package neighbourhood_test
 
 import (
 	"testing"
 
+	"github.com/thanos-fil/planner-demo-go/internal/neighbourhood"
 	"github.com/thanos-fil/planner-demo-go/internal/planner"
 )
 
@@ -11,7 +12,7 @@ func TestNeighbourhoodIntegration(t *testing.T) {
 	net := planner.MockExampleNetwork()
 
 	t.Run("neighbourhood from node 1 in mock network", func(t *testing.T) {
-		result := neighbourhood(net, 1, 3.0)
+		result := neighbourhood.Neighbourhood(net, 1, 3.0)
 
 		// Should include nodes reachable within distance 3.0 from node 1
 		// Based on the mock network: 1 - 5 - 9
@@ -25,7 +26,7 @@ func TestNeighbourhoodIntegration(t *testing.T) {
 		}
 
 		// Check that key nodes are present
-		expectedNodes := []PipeID{1, 5, 9}
+		expectedNodes := []neighbourhood.PipeID{1, 5, 9}
 		for _, nodeID := range expectedNodes {
 			if _, found := result[nodeID]; !found {
 				t.Errorf("expected node %d to be in result", nodeID)
@@ -34,7 +35,7 @@ func TestNeighbourhoodIntegration(t *testing.T) {
 	})
 
 	t.Run("neighbourhood from node 16 in mock network", func(t *testing.T) {
-		result := neighbourhood(net, 16, 4.0)
+		result := neighbourhood.Neighbourhood(net, 16, 4.0)
 
 		// Should include nodes reachable within distance 4.0 from node 16
 		// Based on the mock network: 16 - 12 - 11
@@ -48,7 +49,7 @@ func TestNeighbourhoodIntegration(t *testing.T) {
 		}
 
 		// Check that key nodes are present
-		expectedNodes := []PipeID{16, 12, 11}
+		expectedNodes := []neighbourhood.PipeID{16, 12, 11}
 		for _, nodeID := range expectedNodes {
 			if _, found := result[nodeID]; !found {
 				t.Errorf("expected node %d to be in result", nodeID)
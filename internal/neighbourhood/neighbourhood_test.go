@@ -201,3 +201,27 @@ func TestNeighbourhoodEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestNeighbourhoodInvalidNetwork(t *testing.T) {
+	net := graph.New()
+	net.AddNode(&graph.Node{ID: 0, Score: 0.0, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 1, Score: 0.0, Length: 1.0})
+	net.AddUndirectedEdge(0, 1)
+	net.AddUndirectedEdge(1, 7) // 7 does not exist
+
+	t.Run("dangling edge is skipped", func(t *testing.T) {
+		result := neighbourhood(net, 0, 10.0)
+
+		if len(result) != 2 {
+			t.Errorf("expected 2 nodes, got %d", len(result))
+		}
+	})
+
+	t.Run("unknown root", func(t *testing.T) {
+		result := neighbourhood(net, 42, 10.0)
+
+		if len(result) != 0 {
+			t.Errorf("expected 0 nodes, got %d", len(result))
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package neighbourhood
+
+import (
+	"sort"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// Options tunes a search. The zero value runs the plain search.
+type Options struct {
+	Tracer  Tracer   // Receives every step of the search (nil for none)
+	Cost    CostFunc // Cost of stepping onto a pipe, counted against the budget (nil means LengthCost)
+	CostKey string   // Names Cost for Cache; searches with a Cost but no CostKey are not cached
+	Growth  Growth   // How the region grows (ShortestPath by default)
+
+	// Exclude blocks pipes: they are never reached and the search does not
+	// pass through them, even when they are roots.
+	Exclude map[PipeID]struct{}
+	// Include lists pipes that must be reached. Those that are not roots
+	// already become extra roots with the budget of the first seed, or just
+	// enough budget for themselves if they cost more. Exclude wins over
+	// Include.
+	Include map[PipeID]struct{}
+}
+
+// Growth selects how a search grows its region.
+type Growth int
+
+const (
+	// ShortestPath reaches every pipe whose shortest path from a root costs
+	// at most the root's budget.
+	ShortestPath Growth = iota
+	// RiskGreedy grows one connected cluster per root, always adding the
+	// frontier pipe with the highest Score per unit of cost, until the
+	// cluster's total cost would exceed the root's budget.
+	RiskGreedy
+)
+
+// CostFunc returns the cost of stepping from pipe from onto pipe to. from is
+// nil when to is a root. Costs must be non-negative; the search adds them up
+// along a path and compares the sum with the budget.
+type CostFunc func(from, to *graph.Node) float64
+
+// LengthCost is the default cost: the length of the pipe stepped onto, so
+// the budget limits the length of a path.
+func LengthCost(_, to *graph.Node) float64 { return to.Length }
+
+// NodeCost turns a per-pipe cost into a CostFunc that ignores where the path
+// comes from, e.g. NodeCost(func(n *graph.Node) float64 { return n.Length * euroPerMetre[n.Material] }).
+func NodeCost(cost func(*graph.Node) float64) CostFunc {
+	return func(_, to *graph.Node) float64 { return cost(to) }
+}
+
+func (o Options) cost() CostFunc {
+	if o.Cost == nil {
+		return LengthCost
+	}
+	return o.Cost
+}
+
+func (o Options) excluded(id PipeID) bool {
+	_, ok := o.Exclude[id]
+	return ok
+}
+
+// roots returns seeds without excluded roots, followed by the Include pipes
+// that are not seeds yet, in ascending ID order.
+func (o Options) roots(net *graph.Network, seeds []Seed) []Seed {
+	if len(o.Exclude) == 0 && len(o.Include) == 0 {
+		return seeds
+	}
+
+	var budget float64
+	if len(seeds) > 0 {
+		budget = seeds[0].Budget
+	}
+	roots := make([]Seed, 0, len(seeds)+len(o.Include))
+	isRoot := make(map[PipeID]bool, len(seeds))
+	for _, s := range seeds {
+		if !o.excluded(s.Root) {
+			roots = append(roots, s)
+			isRoot[s.Root] = true
+		}
+	}
+
+	var extra []PipeID
+	for id := range o.Include {
+		if _, ok := net.Nodes[id]; ok && !isRoot[id] && !o.excluded(id) {
+			extra = append(extra, id)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	cost := o.cost()
+	for _, id := range extra {
+		roots = append(roots, Seed{Root: id, Budget: max(budget, cost(nil, net.Nodes[id]))})
+	}
+	return roots
+}
@@ -0,0 +1,89 @@
+package neighbourhood
+
+import "sort"
+
+// Result is the shortest-path tree (or forest, for several roots) built by a
+// search. Every pipe reached has an entry in Dist and Source; every pipe
+// except the roots also has one in Pred.
+type Result struct {
+	Dist   map[PipeID]float64 // Cumulative length (or Options.Cost) of the shortest path, including both end pipes
+	Pred   map[PipeID]PipeID  // Previous pipe on the shortest path
+	Source map[PipeID]PipeID  // Root the shortest path starts from
+}
+
+func newResult() *Result {
+	return &Result{
+		Dist:   make(map[PipeID]float64),
+		Pred:   make(map[PipeID]PipeID),
+		Source: make(map[PipeID]PipeID),
+	}
+}
+
+func (r *Result) add(id PipeID, cumLen float64, src, from PipeID) {
+	r.Dist[id] = cumLen
+	r.Source[id] = src
+	if from != id {
+		r.Pred[id] = from
+	}
+}
+
+// Len returns the number of pipes reached.
+func (r *Result) Len() int { return len(r.Dist) }
+
+// Contains reports whether id was reached.
+func (r *Result) Contains(id PipeID) bool {
+	_, ok := r.Dist[id]
+	return ok
+}
+
+// Pipes returns the set of pipes reached, in the form Neighbourhood returns.
+func (r *Result) Pipes() map[PipeID]struct{} {
+	set := make(map[PipeID]struct{}, len(r.Dist))
+	for id := range r.Dist {
+		set[id] = struct{}{}
+	}
+	return set
+}
+
+// Sorted returns the pipes reached in ascending ID order.
+func (r *Result) Sorted() []PipeID {
+	ids := make([]PipeID, 0, len(r.Dist))
+	for id := range r.Dist {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Path returns the shortest path from the root to id, both included, or nil
+// if id was not reached.
+func (r *Result) Path(id PipeID) []PipeID {
+	if !r.Contains(id) {
+		return nil
+	}
+	path := []PipeID{id}
+	for {
+		prev, ok := r.Pred[id]
+		if !ok {
+			break
+		}
+		path = append(path, prev)
+		id = prev
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Children returns the shortest-path tree as child lists, each in ascending
+// ID order. Leaves have no entry.
+func (r *Result) Children() map[PipeID][]PipeID {
+	children := make(map[PipeID][]PipeID)
+	for _, id := range r.Sorted() {
+		if prev, ok := r.Pred[id]; ok {
+			children[prev] = append(children[prev], id)
+		}
+	}
+	return children
+}
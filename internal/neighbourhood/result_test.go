@@ -0,0 +1,83 @@
+package neighbourhood
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestSearch(t *testing.T) {
+	// Diamond with a tail: 0 - 1 - 3 - 4 and 0 - 2 - 3, where 1 is shorter than 2
+	net := graph.New()
+	net.AddNode(&graph.Node{ID: 0, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 1, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 2, Length: 2.0})
+	net.AddNode(&graph.Node{ID: 3, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 4, Length: 0.5})
+	net.AddUndirectedEdge(0, 1)
+	net.AddUndirectedEdge(0, 2)
+	net.AddUndirectedEdge(1, 3)
+	net.AddUndirectedEdge(2, 3)
+	net.AddUndirectedEdge(3, 4)
+
+	res := Search(net, 0, 4.0)
+
+	// 0: 1.0, 1: 1.0+1.0, 2: 1.0+2.0, 3: 2.0+1.0 via 1, 4: 3.0+0.5
+	expectedDist := map[PipeID]float64{0: 1.0, 1: 2.0, 2: 3.0, 3: 3.0, 4: 3.5}
+	if !reflect.DeepEqual(res.Dist, expectedDist) {
+		t.Errorf("expected distances %v, got %v", expectedDist, res.Dist)
+	}
+	expectedPred := map[PipeID]PipeID{1: 0, 2: 0, 3: 1, 4: 3}
+	if !reflect.DeepEqual(res.Pred, expectedPred) {
+		t.Errorf("expected predecessors %v, got %v", expectedPred, res.Pred)
+	}
+	for id, src := range res.Source {
+		if src != 0 {
+			t.Errorf("expected pipe %d to come from root 0, got %d", id, src)
+		}
+	}
+
+	if got, want := res.Path(4), []PipeID{0, 1, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected path %v, got %v", want, got)
+	}
+	if got, want := res.Path(0), []PipeID{0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected path %v, got %v", want, got)
+	}
+	if got := res.Path(99); got != nil {
+		t.Errorf("expected no path to unreached pipe, got %v", got)
+	}
+
+	expectedChildren := map[PipeID][]PipeID{0: {1, 2}, 1: {3}, 3: {4}}
+	if !reflect.DeepEqual(res.Children(), expectedChildren) {
+		t.Errorf("expected children %v, got %v", expectedChildren, res.Children())
+	}
+
+	if !reflect.DeepEqual(res.Pipes(), Neighbourhood(net, 0, 4.0)) {
+		t.Errorf("expected Search and Neighbourhood to reach the same pipes")
+	}
+}
+
+func TestSearchMultiPaths(t *testing.T) {
+	// Chain 0 - 1 - 2 - 3 - 4, all of length 1.0, roots at both ends
+	net := graph.New()
+	for id := graph.ID(0); id < 5; id++ {
+		net.AddNode(&graph.Node{ID: id, Length: 1.0})
+	}
+	for id := graph.ID(0); id < 4; id++ {
+		net.AddUndirectedEdge(id, id+1)
+	}
+
+	res := SearchMulti(net, []Seed{{Root: 0, Budget: 3.0}, {Root: 4, Budget: 2.0}})
+
+	// 2 is 3.0 from 0 (no budget left) and out of reach of 4
+	if got, want := res.Path(2), []PipeID{0, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected path %v, got %v", want, got)
+	}
+	if got, want := res.Path(3), []PipeID{4, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected path %v, got %v", want, got)
+	}
+	if res.Dist[3] != 2.0 {
+		t.Errorf("expected distance 2.0 to pipe 3, got %v", res.Dist[3])
+	}
+}
@@ -0,0 +1,63 @@
+package neighbourhood
+
+import (
+	"sort"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// Sweep lists the pipes reached from a root in the order they join as the
+// budget grows, i.e. by cumulative length (ties by ID). The neighbourhood
+// for any budget up to the one the sweep was built with is a prefix of it,
+// so budget curves need a single search.
+type Sweep struct {
+	Root        PipeID
+	MaxLen      float64   // Budget the sweep was built with
+	Pipes       []PipeID  // Pipes in join order
+	Dist        []float64 // Dist[i] is the cumulative length at which Pipes[i] joins
+	TotalLength []float64 // TotalLength[i] is the summed length of Pipes[:i+1]
+	TotalRisk   []float64 // TotalRisk[i] is the summed score of Pipes[:i+1]
+}
+
+// NewSweep runs the search from root once with budget maxLen and records the
+// join order.
+func NewSweep(net *graph.Network, root graph.ID, maxLen float64) *Sweep {
+	res := Search(net, root, maxLen)
+	s := &Sweep{Root: root, MaxLen: maxLen, Pipes: res.Sorted()}
+	sort.SliceStable(s.Pipes, func(i, j int) bool { return res.Dist[s.Pipes[i]] < res.Dist[s.Pipes[j]] })
+
+	s.Dist = make([]float64, len(s.Pipes))
+	s.TotalLength = make([]float64, len(s.Pipes))
+	s.TotalRisk = make([]float64, len(s.Pipes))
+	var length, risk float64
+	for i, id := range s.Pipes {
+		node := net.Nodes[id]
+		length += node.Length
+		risk += node.Score
+		s.Dist[i], s.TotalLength[i], s.TotalRisk[i] = res.Dist[id], length, risk
+	}
+	return s
+}
+
+// Count returns how many pipes are reached with the given budget. Budgets
+// above MaxLen are treated as MaxLen.
+func (s *Sweep) Count(budget float64) int {
+	return sort.Search(len(s.Dist), func(i int) bool { return s.Dist[i] > budget })
+}
+
+// Prefix returns the pipes reached with the given budget, in join order. The
+// slice shares memory with the sweep and must not be modified.
+func (s *Sweep) Prefix(budget float64) []PipeID {
+	return s.Pipes[:s.Count(budget)]
+}
+
+// At returns the totals for the given budget as Batch would report them,
+// except that Pipes is in join order and shares memory with the sweep.
+func (s *Sweep) At(budget float64) Summary {
+	n := s.Count(budget)
+	sum := Summary{Root: s.Root, Count: n, Pipes: s.Pipes[:n:n]}
+	if n > 0 {
+		sum.TotalLength, sum.TotalRisk = s.TotalLength[n-1], s.TotalRisk[n-1]
+	}
+	return sum
+}
@@ -0,0 +1,38 @@
+package neighbourhood
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSweep(t *testing.T) {
+	net := gridNetwork(15, 9)
+	s := NewSweep(net, 7, 400)
+
+	for i := 1; i < len(s.Dist); i++ {
+		if s.Dist[i] < s.Dist[i-1] {
+			t.Fatalf("join order not sorted at %d: %v after %v", i, s.Dist[i], s.Dist[i-1])
+		}
+	}
+
+	for _, budget := range []float64{0, 50, 120, 250, 399.5, 400} {
+		want := Search(net, 7, budget)
+
+		got := append([]PipeID(nil), s.Prefix(budget)...)
+		sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+		if !reflect.DeepEqual(got, want.Sorted()) && !(len(got) == 0 && want.Len() == 0) {
+			t.Errorf("budget %.1f: expected %v, got %v", budget, want.Sorted(), got)
+		}
+
+		sum := s.At(budget)
+		var length float64
+		for _, id := range got {
+			length += net.Nodes[id].Length
+		}
+		if sum.Count != want.Len() || !almostEqual(sum.TotalLength, length) {
+			t.Errorf("budget %.1f: expected %d pipes of %.3f, got %d of %.3f",
+				budget, want.Len(), length, sum.Count, sum.TotalLength)
+		}
+	}
+}
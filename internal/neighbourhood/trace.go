@@ -0,0 +1,65 @@
+package neighbourhood
+
+import "fmt"
+
+// EventKind identifies a step of the search reported to a Tracer.
+type EventKind int
+
+const (
+	Push  EventKind = iota // A path to a pipe not queued before is queued
+	Relax                  // A shorter path to an already queued pipe is queued; the old entry goes stale
+	Pop                    // A queued path is dequeued and its pipe is reached
+	Prune                  // A queued path is dequeued and dropped because it exceeds its budget
+	Skip                   // A path is dropped because a better one to the same pipe exists
+)
+
+var eventKindNames = [...]string{
+	Push:  "push",
+	Relax: "relax",
+	Pop:   "pop",
+	Prune: "prune",
+	Skip:  "skip",
+}
+
+func (k EventKind) String() string {
+	if k >= 0 && int(k) < len(eventKindNames) {
+		return eventKindNames[k]
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event is one step of the search.
+type Event struct {
+	Kind     EventKind
+	Pipe     PipeID
+	From     PipeID  // Previous pipe on the path (Pipe itself for a root)
+	Source   PipeID  // Root the path starts from
+	CumLen   float64 // Cumulative length (or Options.Cost) of the path, including Pipe
+	Budget   float64 // Budget of Source
+	Prev     float64 // Relax and Skip: cumulative length of the path replaced or kept instead
+	Dequeued bool    // Skip: the path was taken off the queue (a stale entry) rather than never queued
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case Relax:
+		return fmt.Sprintf("relax %d from %d: %v (was %v)", e.Pipe, e.From, e.CumLen, e.Prev)
+	case Skip:
+		return fmt.Sprintf("skip %d from %d: %v (have %v)", e.Pipe, e.From, e.CumLen, e.Prev)
+	case Prune:
+		return fmt.Sprintf("prune %d: %v > %v", e.Pipe, e.CumLen, e.Budget)
+	}
+	return fmt.Sprintf("%s %d from %d: %v", e.Kind, e.Pipe, e.From, e.CumLen)
+}
+
+// Tracer observes a search step by step. Events arrive in the order the
+// search performs them, on the goroutine running the search.
+type Tracer interface {
+	Trace(Event)
+}
+
+// TracerFunc adapts an ordinary function to the Tracer interface.
+type TracerFunc func(Event)
+
+// Trace calls f(e).
+func (f TracerFunc) Trace(e Event) { f(e) }
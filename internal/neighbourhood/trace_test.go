@@ -0,0 +1,113 @@
+package neighbourhood
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestSearchTracer(t *testing.T) {
+	// Triangle 0 - 1 - 2 - 0 with 2 long, plus 3 hanging off 2:
+	// 2 is reached directly from 0 (1.0 + 3.0) before 1 offers 2.0 + 3.0
+	net := graph.New()
+	net.AddNode(&graph.Node{ID: 0, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 1, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 2, Length: 3.0})
+	net.AddNode(&graph.Node{ID: 3, Length: 1.0})
+	net.AddUndirectedEdge(0, 1)
+	net.AddUndirectedEdge(0, 2)
+	net.AddUndirectedEdge(1, 2)
+	net.AddUndirectedEdge(2, 3)
+
+	var kinds []EventKind
+	var pipes []PipeID
+	tracer := TracerFunc(func(e Event) {
+		t.Log(e)
+		kinds = append(kinds, e.Kind)
+		pipes = append(pipes, e.Pipe)
+	})
+	res := SearchWith(net, []Seed{{Root: 0, Budget: 4.5}}, Options{Tracer: tracer})
+
+	expectedKinds := []EventKind{
+		Push, Pop, // 0: 1.0
+		Push, Push, // 1: 2.0, 2: 4.0
+		Pop,        // 1
+		Skip, Skip, // 0 and 2 (5.0 >= 4.0) via 1
+		Pop,        // 2
+		Skip, Skip, // 0 and 1 via 2
+		Push,  // 3: 5.0
+		Prune, // 3 exceeds 4.5
+	}
+	expectedPipes := []PipeID{0, 0, 1, 2, 1, 0, 2, 2, 0, 1, 3, 3}
+	if !reflect.DeepEqual(kinds, expectedKinds) {
+		t.Errorf("expected events %v, got %v", expectedKinds, kinds)
+	}
+	if !reflect.DeepEqual(pipes, expectedPipes) {
+		t.Errorf("expected pipes %v, got %v", expectedPipes, pipes)
+	}
+	if res.Len() != 3 {
+		t.Errorf("expected 3 pipes reached, got %d", res.Len())
+	}
+}
+
+func TestSearchTracerRelax(t *testing.T) {
+	// Chain 0 - 1 - 2, all of length 1.0. Root 2 has far more budget than
+	// root 0, so its path to 0 (3.0 of 10.0) leaves more budget than 0's
+	// own entry (1.0 of 3.0) and replaces it.
+	net := graph.New()
+	for id := graph.ID(0); id < 3; id++ {
+		net.AddNode(&graph.Node{ID: id, Length: 1.0})
+	}
+	net.AddUndirectedEdge(0, 1)
+	net.AddUndirectedEdge(1, 2)
+
+	var relaxed, stale []Event
+	res := SearchWith(net, []Seed{{Root: 0, Budget: 3.0}, {Root: 2, Budget: 10.0}}, Options{
+		Tracer: TracerFunc(func(e Event) {
+			switch {
+			case e.Kind == Relax:
+				relaxed = append(relaxed, e)
+			case e.Kind == Skip && e.Dequeued:
+				stale = append(stale, e)
+			}
+		}),
+	})
+
+	expected := []Event{{Kind: Relax, Pipe: 0, From: 1, Source: 2, CumLen: 3.0, Budget: 10.0, Prev: 1.0}}
+	if !reflect.DeepEqual(relaxed, expected) {
+		t.Errorf("expected relax events %v, got %v", expected, relaxed)
+	}
+	// The replaced entry of root 0 is dropped when it comes off the queue
+	if len(stale) != 1 || stale[0].Pipe != 0 || stale[0].Source != 0 {
+		t.Errorf("expected the old entry of 0 to be skipped, got %v", stale)
+	}
+	if res.Source[0] != 2 {
+		t.Errorf("expected 0 to be reached from 2, got %d", res.Source[0])
+	}
+}
+
+func TestSearchTieBreakByID(t *testing.T) {
+	// Star with centre 0 and leaves added in the order 3, 1, 2, all of
+	// length 1.0: the leaves tie at 2.0 and must pop in ID order
+	net := graph.New()
+	for id := graph.ID(0); id < 4; id++ {
+		net.AddNode(&graph.Node{ID: id, Length: 1.0})
+	}
+	net.AddUndirectedEdge(0, 3)
+	net.AddUndirectedEdge(0, 1)
+	net.AddUndirectedEdge(0, 2)
+
+	var popped []PipeID
+	SearchWith(net, []Seed{{Root: 0, Budget: 2.0}}, Options{Tracer: TracerFunc(func(e Event) {
+		if e.Kind == Pop {
+			popped = append(popped, e.Pipe)
+		}
+	})})
+	if expected := []PipeID{0, 1, 2, 3}; !reflect.DeepEqual(popped, expected) {
+		t.Errorf("expected pop order %v, got %v", expected, popped)
+	}
+	if got, expected := NeighbourhoodSorted(net, 0, 2.0), []PipeID{0, 1, 2, 3}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,304 @@
+// Package netgen generates synthetic pipe networks for demos, tests and
+// benchmarks. Generation is fully determined by Options, including the
+// random seed, so every network can be reproduced.
+package netgen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// Topology selects the shape of the generated network.
+type Topology int
+
+const (
+	Grid  Topology = iota // Urban street grid
+	Tree                  // Rural network: long branches with short spurs, no loops
+	Ring                  // Ring mains linked to each other
+	Towns                 // Dense town grids joined by long trunk mains
+)
+
+var topologyNames = [...]string{
+	Grid:  "grid",
+	Tree:  "tree",
+	Ring:  "ring",
+	Towns: "towns",
+}
+
+func (t Topology) String() string {
+	if t >= 0 && int(t) < len(topologyNames) {
+		return topologyNames[t]
+	}
+	return fmt.Sprintf("Topology(%d)", int(t))
+}
+
+// ParseTopology returns the topology with the given name ("grid", "tree",
+// "ring" or "towns").
+func ParseTopology(name string) (Topology, error) {
+	for t, n := range topologyNames {
+		if n == name {
+			return Topology(t), nil
+		}
+	}
+	return 0, fmt.Errorf("netgen: unknown topology %q", name)
+}
+
+// BaseYear is the default reference year for install years, fixed so that
+// a seed gives the same network whenever it is run.
+const BaseYear = 2025
+
+// Options configures Generate. Zero fields take the documented defaults.
+type Options struct {
+	Seed          int64      // Random seed
+	Pipes         int        // Number of pipes (default 1000)
+	Topology      Topology   // Network shape (default Grid)
+	GridSize      int        // Grid: pipes per row (default sqrt(Pipes))
+	EdgeProb      float64    // Grid and Towns: probability each adjacent link exists (values <= 0 mean the default 1)
+	CrossLinkRate float64    // Probability per pipe of one extra link to a random pipe (default 0)
+	Branching     int        // Tree: spurs grow off the last Branching pipes of a branch (default 3)
+	RingSize      int        // Ring: pipes per ring main (default 12)
+	TownCount     int        // Towns: number of towns (default 4)
+	TrunkPipes    int        // Towns: pipes in the trunk main between two towns (default 3)
+	MinLength     float64    // Shortest pipe in metres (default 10)
+	MaxLength     float64    // Longest pipe in metres, trunk mains excepted (default 100)
+	LengthDist    LengthDist // Pipe length distribution (default uniform between MinLength and MaxLength)
+	MaxAge        int        // Oldest pipe in years (default 80)
+	Year          int        // Reference year for InstallYear (default BaseYear)
+	LoF           LoFModel   // Likelihood of failure model (default DefaultLoF)
+}
+
+func (o Options) withDefaults() Options {
+	if o.Pipes <= 0 {
+		o.Pipes = 1000
+	}
+	if o.GridSize <= 0 {
+		o.GridSize = max(1, int(math.Sqrt(float64(o.Pipes))))
+	}
+	if o.EdgeProb <= 0 {
+		o.EdgeProb = 1
+	}
+	if o.Branching <= 0 {
+		o.Branching = 3
+	}
+	if o.RingSize <= 2 {
+		o.RingSize = 12
+	}
+	if o.TownCount <= 0 {
+		o.TownCount = 4
+	}
+	if o.TrunkPipes <= 0 {
+		o.TrunkPipes = 3
+	}
+	if o.MinLength <= 0 {
+		o.MinLength = 10
+	}
+	if o.MaxLength < o.MinLength {
+		o.MaxLength = max(100, o.MinLength)
+	}
+	if o.LengthDist == nil {
+		o.LengthDist = Uniform(o.MinLength, o.MaxLength)
+	}
+	if o.MaxAge <= 0 {
+		o.MaxAge = 80
+	}
+	if o.Year == 0 {
+		o.Year = BaseYear
+	}
+	if o.LoF == nil {
+		o.LoF = DefaultLoF
+	}
+	return o
+}
+
+// LengthDist draws the length of a pipe in metres from rng.
+type LengthDist func(rng *rand.Rand) float64
+
+// Uniform returns a distribution of lengths spread evenly between lo and
+// hi.
+func Uniform(lo, hi float64) LengthDist {
+	return func(rng *rand.Rand) float64 { return lo + rng.Float64()*(hi-lo) }
+}
+
+// Factors are the simulated properties a pipe's likelihood of failure is
+// derived from, each in [0, 1).
+type Factors struct {
+	Age             float64 // Fraction of Options.MaxAge
+	MaterialQuality float64 // 0 is the worst material, 1 the best
+	Environment     float64 // Soil, traffic and pressure stress
+}
+
+// LoFModel turns the factors of a pipe into its likelihood of failure.
+type LoFModel func(Factors) float64
+
+// DefaultLoF weighs age most, then material and environment, and caps the
+// result at 0.9.
+func DefaultLoF(f Factors) float64 {
+	return math.Min(0.9, 0.1+f.Age*0.3+(1-f.MaterialQuality)*0.2+f.Environment*0.1)
+}
+
+// Material returns the pipe material for a material quality factor.
+func Material(quality float64) string {
+	switch {
+	case quality < 0.25:
+		return "cast iron"
+	case quality < 0.5:
+		return "asbestos cement"
+	case quality < 0.75:
+		return "ductile iron"
+	default:
+		return "PVC"
+	}
+}
+
+// Generate builds a network of opts.Pipes pipes with IDs 0..Pipes-1. Every
+// pipe gets a length, material, install year, "environment" attribute and a
+// score from the LoF model. The network has no self-loops or duplicate
+// edges.
+func Generate(opts Options) *graph.Network {
+	opts = opts.withDefaults()
+	g := &generator{
+		opts:  opts,
+		rng:   rand.New(rand.NewSource(opts.Seed)),
+		net:   graph.New(),
+		links: make(map[[2]graph.ID]bool),
+	}
+
+	for i := 0; i < opts.Pipes; i++ {
+		g.addPipe(graph.ID(i))
+	}
+
+	switch opts.Topology {
+	case Tree:
+		g.tree()
+	case Ring:
+		g.ring()
+	case Towns:
+		g.towns()
+	default:
+		g.grid(0, opts.Pipes, opts.GridSize)
+	}
+
+	if opts.CrossLinkRate > 0 {
+		for i := 0; i < opts.Pipes; i++ {
+			if g.rng.Float64() < opts.CrossLinkRate {
+				g.link(graph.ID(i), graph.ID(g.rng.Intn(opts.Pipes)))
+			}
+		}
+	}
+	return g.net
+}
+
+type generator struct {
+	opts  Options
+	rng   *rand.Rand
+	net   *graph.Network
+	links map[[2]graph.ID]bool
+}
+
+func (g *generator) addPipe(id graph.ID) {
+	o := g.opts
+	f := Factors{Age: g.rng.Float64(), MaterialQuality: g.rng.Float64(), Environment: g.rng.Float64()}
+	g.net.AddNode(&graph.Node{
+		ID:          id,
+		Score:       o.LoF(f),
+		Length:      o.LengthDist(g.rng),
+		Material:    Material(f.MaterialQuality),
+		InstallYear: o.Year - int(f.Age*float64(o.MaxAge)),
+		Attrs:       map[string]string{"environment": fmt.Sprintf("%.2f", f.Environment)},
+	})
+}
+
+// link connects a and b unless that would make a self-loop or duplicate.
+func (g *generator) link(a, b graph.ID) {
+	if a == b {
+		return
+	}
+	if a > b {
+		a, b = b, a
+	}
+	if g.links[[2]graph.ID{a, b}] {
+		return
+	}
+	g.links[[2]graph.ID{a, b}] = true
+	g.net.AddUndirectedEdge(a, b)
+}
+
+// grid lays pipes first..first+n-1 out in rows of size and links each to
+// its right and lower neighbour with probability EdgeProb.
+func (g *generator) grid(first, n, size int) {
+	for i := 0; i < n; i++ {
+		if i%size < size-1 && i+1 < n && g.rng.Float64() < g.opts.EdgeProb {
+			g.link(graph.ID(first+i), graph.ID(first+i+1))
+		}
+		if i+size < n && g.rng.Float64() < g.opts.EdgeProb {
+			g.link(graph.ID(first+i), graph.ID(first+i+size))
+		}
+	}
+}
+
+// tree attaches every pipe to one of the last Branching pipes before it,
+// which gives long branches with short spurs.
+func (g *generator) tree() {
+	for i := 1; i < g.opts.Pipes; i++ {
+		parent := max(0, i-1-g.rng.Intn(g.opts.Branching))
+		g.link(graph.ID(parent), graph.ID(i))
+	}
+}
+
+// ring closes every RingSize consecutive pipes into a loop and links each
+// ring to the previous one at a random pipe of both.
+func (g *generator) ring() {
+	n, size := g.opts.Pipes, g.opts.RingSize
+	for start := 0; start < n; start += size {
+		end := min(start+size, n)
+		for i := start; i+1 < end; i++ {
+			g.link(graph.ID(i), graph.ID(i+1))
+		}
+		if end-start > 2 {
+			g.link(graph.ID(end-1), graph.ID(start))
+		}
+		if start > 0 {
+			prev := start - size
+			g.link(graph.ID(prev+g.rng.Intn(size)), graph.ID(start+g.rng.Intn(end-start)))
+		}
+	}
+}
+
+// towns splits the pipes into TownCount square grids joined in a chain by
+// trunk mains of TrunkPipes pipes each, 1.5 to 3 times as long as MaxLength.
+func (g *generator) towns() {
+	o := g.opts
+	trunks := o.TrunkPipes * (o.TownCount - 1)
+	if o.TownCount < 2 || o.Pipes-trunks < o.TownCount {
+		g.grid(0, o.Pipes, o.GridSize)
+		return
+	}
+
+	townPipes := (o.Pipes - trunks) / o.TownCount
+	next := 0
+	var prevTown []int // first and size of the previous town
+	for t := 0; t < o.TownCount; t++ {
+		size := townPipes
+		if t == o.TownCount-1 {
+			size = o.Pipes - trunks - townPipes*(o.TownCount-1)
+		}
+		if t > 0 {
+			// Trunk main from a random pipe of the previous town to this one
+			from := graph.ID(prevTown[0] + g.rng.Intn(prevTown[1]))
+			for k := 0; k < o.TrunkPipes; k++ {
+				id := graph.ID(next)
+				g.net.Nodes[id].Length = 3 * o.MaxLength * (0.5 + g.rng.Float64()/2)
+				g.link(from, id)
+				from = id
+				next++
+			}
+			g.link(from, graph.ID(next+g.rng.Intn(size)))
+		}
+		g.grid(next, size, max(1, int(math.Sqrt(float64(size)))))
+		prevTown = []int{next, size}
+		next += size
+	}
+}
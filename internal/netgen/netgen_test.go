@@ -0,0 +1,92 @@
+package netgen
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		components int // expected connected components, 0 to skip
+	}{
+		{name: "grid", opts: Options{Pipes: 400}, components: 1},
+		{name: "grid with cross-links", opts: Options{Pipes: 400, EdgeProb: 0.7, CrossLinkRate: 0.1}},
+		{name: "tree", opts: Options{Pipes: 300, Topology: Tree}, components: 1},
+		{name: "ring", opts: Options{Pipes: 100, Topology: Ring, RingSize: 10}, components: 1},
+		{name: "towns", opts: Options{Pipes: 500, Topology: Towns, TownCount: 3}, components: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Seed = 42
+			net := Generate(tt.opts)
+
+			if len(net.Nodes) != tt.opts.Pipes {
+				t.Fatalf("expected %d pipes, got %d", tt.opts.Pipes, len(net.Nodes))
+			}
+			report := net.Validate()
+			if !report.Valid() {
+				t.Errorf("expected a valid network, got %v", report.Err())
+			}
+			if n := len(net.Components()); tt.components > 0 && n != tt.components {
+				t.Errorf("expected %d components, got %d", tt.components, n)
+			}
+			for id, node := range net.Nodes {
+				if node.Score < 0 || node.Score > 0.9 || node.Material == "" || node.InstallYear > BaseYear {
+					t.Fatalf("pipe %d has unexpected attributes: %+v", id, node)
+				}
+			}
+
+			if !reflect.DeepEqual(net, Generate(tt.opts)) {
+				t.Errorf("expected the same seed to give the same network")
+			}
+			tt.opts.Seed = 43
+			if reflect.DeepEqual(net, Generate(tt.opts)) {
+				t.Errorf("expected a different seed to give a different network")
+			}
+		})
+	}
+}
+
+func TestGenerateTreeHasNoLoops(t *testing.T) {
+	net := Generate(Options{Seed: 1, Pipes: 200, Topology: Tree})
+	edges := 0
+	for _, nbrs := range net.Edges {
+		edges += len(nbrs)
+	}
+	// A tree on n pipes has n-1 links, each listed from both ends
+	if edges != 2*(len(net.Nodes)-1) {
+		t.Errorf("expected %d adjacency entries, got %d", 2*(len(net.Nodes)-1), edges)
+	}
+}
+
+func TestGenerateLoFModel(t *testing.T) {
+	constant := func(Factors) float64 { return 0.5 }
+	net := Generate(Options{Seed: 1, Pipes: 50, LoF: constant})
+	for id, node := range net.Nodes {
+		if node.Score != 0.5 {
+			t.Fatalf("pipe %d: expected score 0.5 from the custom model, got %v", id, node.Score)
+		}
+	}
+}
+
+func TestGenerateLengthDist(t *testing.T) {
+	constant := func(*rand.Rand) float64 { return 42 }
+	net := Generate(Options{Seed: 1, Pipes: 50, LengthDist: constant})
+	for id, node := range net.Nodes {
+		if node.Length != 42 {
+			t.Fatalf("pipe %d: expected length 42 from the custom distribution, got %v", id, node.Length)
+		}
+	}
+
+	// The default is Uniform(MinLength, MaxLength)
+	opts := Options{Seed: 1, Pipes: 50, MinLength: 20, MaxLength: 30}
+	uniform := opts
+	uniform.LengthDist = Uniform(20, 30)
+	if !reflect.DeepEqual(Generate(opts), Generate(uniform)) {
+		t.Errorf("expected the default lengths to match Uniform(MinLength, MaxLength)")
+	}
+}
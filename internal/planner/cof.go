@@ -0,0 +1,103 @@
+package planner
+
+import (
+	"strconv"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// Attributes read by FailureModeModel, see graph.Node.Attr.
+const (
+	AttrCustomers         = "customers"          // Customers served through the pipe
+	AttrCriticalCustomers = "critical_customers" // Hospitals, care homes and the like among them
+)
+
+// CoFModel gives the expected cost of one failure of a pipe, in €.
+type CoFModel interface {
+	CoF(node *graph.Node) float64
+}
+
+// FailureMode is one way a pipe can fail and what it costs when it does.
+type FailureMode struct {
+	Name        string
+	Probability float64 // Share of failures that are of this mode
+	Cost        float64 // Direct cost in € (repair, water loss, damage) at the reference diameter
+	PerCustomer float64 // € per customer served
+	PerCritical float64 // € per critical customer served
+}
+
+// consequence is the cost of one failure of this mode, before the zone factor.
+func (m FailureMode) consequence(diameterFactor, customers, critical float64) float64 {
+	return m.Cost*diameterFactor + m.PerCustomer*customers + m.PerCritical*critical
+}
+
+// FailureModeModel weighs the consequences of each failure mode by its
+// probability. Direct costs grow in proportion to the pipe diameter relative
+// to ReferenceDiameter; customer costs come from the AttrCustomers and
+// AttrCriticalCustomers attributes, where missing or unparsable values count
+// as 0; and everything is multiplied by the factor of the pipe's zone.
+type FailureModeModel struct {
+	Modes             []FailureMode
+	ReferenceDiameter float64            // Diameter in mm with direct costs as given (0, or an unknown pipe diameter, means no scaling)
+	ZoneFactors       map[string]float64 // Multiplier by graph.Node.Zone (missing zones use 1)
+}
+
+// DefaultCoFModel returns the failure modes used by the demos, with direct
+// costs for a DN150 pipe: minor leaks (55%, €3,000), major leaks (25%,
+// €15,000), bursts (10%, €62,500) and service disruptions (10%, €30,000),
+// the last three also costing per customer and critical customer served.
+func DefaultCoFModel() *FailureModeModel {
+	return &FailureModeModel{
+		Modes: []FailureMode{
+			{Name: "minor leak", Probability: 0.55, Cost: 3000},
+			{Name: "major leak", Probability: 0.25, Cost: 15000, PerCustomer: 20, PerCritical: 2000},
+			{Name: "burst", Probability: 0.10, Cost: 62500, PerCustomer: 100, PerCritical: 10000},
+			{Name: "service disruption", Probability: 0.10, Cost: 30000, PerCustomer: 50, PerCritical: 5000},
+		},
+		ReferenceDiameter: 150,
+	}
+}
+
+// CoF implements CoFModel.
+func (m *FailureModeModel) CoF(node *graph.Node) float64 {
+	diameterFactor := 1.0
+	if m.ReferenceDiameter > 0 && node.Diameter > 0 {
+		diameterFactor = node.Diameter / m.ReferenceDiameter
+	}
+	customers := attrFloat(node, AttrCustomers)
+	critical := attrFloat(node, AttrCriticalCustomers)
+
+	var cof float64
+	for _, mode := range m.Modes {
+		cof += mode.Probability * mode.consequence(diameterFactor, customers, critical)
+	}
+	if f, ok := m.ZoneFactors[node.Zone]; ok {
+		cof *= f
+	}
+	return cof
+}
+
+func attrFloat(node *graph.Node, key string) float64 {
+	s, ok := node.Attr(key)
+	if !ok {
+		return 0
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// BRE returns the business risk exposure of the pipes, the sum of LoF
+// (Node.Score) times CoF over every pipe. Pipes that are not in net are
+// ignored.
+func BRE(net *graph.Network, pipes []graph.ID, model CoFModel) float64 {
+	var bre float64
+	for _, id := range pipes {
+		if node, ok := net.Nodes[id]; ok {
+			bre += node.Score * model.CoF(node)
+		}
+	}
+	return bre
+}
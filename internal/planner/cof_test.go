@@ -0,0 +1,76 @@
+package planner
+
+import (
+	"math"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestDefaultCoFModel(t *testing.T) {
+	model := DefaultCoFModel()
+	model.ZoneFactors = map[string]float64{"centre": 2}
+
+	tests := []struct {
+		name     string
+		node     *graph.Node
+		expected float64
+	}{
+		{
+			// 0.55*3,000 + 0.25*15,000 + 0.1*62,500 + 0.1*30,000
+			name:     "plain pipe",
+			node:     &graph.Node{ID: 1},
+			expected: 14650,
+		},
+		{
+			name:     "reference diameter",
+			node:     &graph.Node{ID: 1, Diameter: 150},
+			expected: 14650,
+		},
+		{
+			name:     "direct costs scale with diameter",
+			node:     &graph.Node{ID: 1, Diameter: 300},
+			expected: 2 * 14650,
+		},
+		{
+			// Per customer: 0.25*20 + 0.1*100 + 0.1*50 = 20
+			// Per critical customer: 0.25*2,000 + 0.1*10,000 + 0.1*5,000 = 2,000
+			name: "customers served",
+			node: &graph.Node{ID: 1, Attrs: map[string]string{
+				AttrCustomers: "100", AttrCriticalCustomers: "1",
+			}},
+			expected: 14650 + 100*20 + 2000,
+		},
+		{
+			name:     "unparsable customers",
+			node:     &graph.Node{ID: 1, Attrs: map[string]string{AttrCustomers: "many"}},
+			expected: 14650,
+		},
+		{
+			name:     "zone factor",
+			node:     &graph.Node{ID: 1, Zone: "centre"},
+			expected: 2 * 14650,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.CoF(tt.node); math.Abs(got-tt.expected) > 1e-6 {
+				t.Errorf("expected CoF %.2f, got %.2f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBRE(t *testing.T) {
+	net := graph.New()
+	net.AddNode(&graph.Node{ID: 1, Score: 0.5, Length: 1})
+	net.AddNode(&graph.Node{ID: 2, Score: 0.2, Length: 1, Diameter: 300})
+	model := DefaultCoFModel()
+
+	// 0.5*14,650 + 0.2*29,300; pipe 99 is not in the network
+	expected := 0.5*14650 + 0.2*29300
+	if got := BRE(net, []graph.ID{1, 2, 99}, model); math.Abs(got-expected) > 1e-6 {
+		t.Errorf("expected BRE %.2f, got %.2f", expected, got)
+	}
+}
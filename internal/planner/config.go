@@ -1,8 +1,69 @@
 package planner
 
+import (
+	"fmt"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// UserCfg holds the user-supplied parameters for cluster creation.
 type UserCfg struct {
-	TargetCount         int
-	MaxLength           float64
-	OvershootFactor     float64
-	LongestPathFraction float64
+	TargetCount         int     // Maximum number of clusters to create (<= 0 means no limit)
+	MaxLength           float64 // Target total pipe length per cluster
+	OvershootFactor     float64 // A cluster may grow up to MaxLength*OvershootFactor (values < 1 mean no overshoot)
+	LongestPathFraction float64 // Longest path from the seed is capped at MaxLength*LongestPathFraction (0 means 1)
+	Strict              bool    // Refuse to plan on a network with validation errors
+	AllowOverlap        bool    // Let clusters share pipes, e.g. to generate candidates for SelectPortfolio
+
+	Exclude      []graph.ID   // Pipes kept out of every cluster; growth cannot pass through them
+	Include      []graph.ID   // Pipes that must end up in a cluster
+	SeedStrategy SeedStrategy // Picks the pipes clusters grow from (nil means HighLoF{})
+}
+
+// withDefaults returns a copy of cfg with out-of-range values replaced by
+// their neutral defaults.
+func (cfg UserCfg) withDefaults() UserCfg {
+	if cfg.OvershootFactor < 1 {
+		cfg.OvershootFactor = 1
+	}
+	if cfg.LongestPathFraction <= 0 || cfg.LongestPathFraction > 1 {
+		cfg.LongestPathFraction = 1
+	}
+	if cfg.SeedStrategy == nil {
+		cfg.SeedStrategy = HighLoF{}
+	}
+	return cfg
+}
+
+// constraints holds Exclude and Include as sets.
+type constraints struct {
+	exclude map[graph.ID]struct{}
+	include map[graph.ID]bool
+}
+
+// newConstraints checks cfg.Exclude and cfg.Include against net: every
+// must-include pipe has to exist and may not also be excluded.
+func newConstraints(net *graph.Network, cfg UserCfg) (constraints, error) {
+	c := constraints{
+		exclude: make(map[graph.ID]struct{}, len(cfg.Exclude)),
+		include: make(map[graph.ID]bool, len(cfg.Include)),
+	}
+	for _, id := range cfg.Exclude {
+		c.exclude[id] = struct{}{}
+	}
+	for _, id := range cfg.Include {
+		if _, ok := net.Nodes[id]; !ok {
+			return c, fmt.Errorf("planner: must-include pipe %d does not exist", id)
+		}
+		if _, ok := c.exclude[id]; ok {
+			return c, fmt.Errorf("planner: pipe %d is both excluded and must-include", id)
+		}
+		c.include[id] = true
+	}
+	return c, nil
+}
+
+func (c constraints) excluded(id graph.ID) bool {
+	_, ok := c.exclude[id]
+	return ok
 }
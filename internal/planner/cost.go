@@ -0,0 +1,112 @@
+package planner
+
+import (
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// CostModel prices a renewal project made up of the given pipes, in €.
+type CostModel interface {
+	Cost(net *graph.Network, pipes []graph.ID) float64
+}
+
+// Rate is one row of a TableCostModel: the price per metre for pipes of a
+// material and diameter band.
+type Rate struct {
+	Material    string  // Pipe material ("" matches any)
+	MinDiameter float64 // Band is above this diameter in mm (0 means no lower bound)
+	MaxDiameter float64 // Band is up to and including this diameter in mm (0 means no upper bound)
+	PerMetre    float64 // € per metre
+}
+
+// matches reports whether the rate applies to node. A row with a diameter
+// band never matches a pipe of unknown diameter.
+func (r Rate) matches(node *graph.Node) bool {
+	if r.Material != "" && r.Material != node.Material {
+		return false
+	}
+	if r.MinDiameter <= 0 && r.MaxDiameter <= 0 {
+		return true
+	}
+	d := node.Diameter
+	return d > 0 && d > r.MinDiameter && (r.MaxDiameter <= 0 || d <= r.MaxDiameter)
+}
+
+// Surcharge adds to the cost of every pipe with a matching attribute, e.g.
+// traffic management for pipes under a main road.
+type Surcharge struct {
+	Attr     string  // Attribute name as used by graph.Node.Attr
+	Value    string  // Required value ("" matches any set value)
+	PerMetre float64 // € per metre of a matching pipe
+	PerPipe  float64 // € per matching pipe
+}
+
+func (s Surcharge) matches(node *graph.Node) bool {
+	v, ok := node.Attr(s.Attr)
+	return ok && (s.Value == "" || v == s.Value)
+}
+
+// TableCostModel prices a project as a fixed mobilisation cost plus, for
+// every pipe, its length times the per-metre rate of the first matching
+// Rate row and any matching surcharges. Pipes that match no rate row only
+// pay surcharges. Economies of scale apply to the per-metre works: metres
+// beyond ScaleThreshold are ScaleDiscount cheaper.
+type TableCostModel struct {
+	Rates          []Rate      // Checked in order, the first match wins
+	Surcharges     []Surcharge // All matches apply
+	Mobilisation   float64     // € per project
+	ScaleThreshold float64     // Project length in metres after which ScaleDiscount applies (0 means never)
+	ScaleDiscount  float64     // Fraction taken off the rate of metres beyond ScaleThreshold
+}
+
+// DefaultCostModel returns the table used by the demos: €500/m for pipes of
+// unknown diameter, diameter bands from €450/m to €1,200/m, €100/m extra for
+// removing asbestos cement, €5,000 per pipe under a main road (attribute
+// "road" set to "main"), €10,000 mobilisation and 10% off the works beyond
+// the first kilometre.
+func DefaultCostModel() *TableCostModel {
+	return &TableCostModel{
+		Rates: []Rate{
+			{MaxDiameter: 150, PerMetre: 450},
+			{MinDiameter: 150, MaxDiameter: 300, PerMetre: 600},
+			{MinDiameter: 300, MaxDiameter: 600, PerMetre: 900},
+			{MinDiameter: 600, PerMetre: 1200},
+			{PerMetre: 500},
+		},
+		Surcharges: []Surcharge{
+			{Attr: graph.AttrMaterial, Value: "asbestos cement", PerMetre: 100},
+			{Attr: "road", Value: "main", PerPipe: 5000},
+		},
+		Mobilisation:   10000,
+		ScaleThreshold: 1000,
+		ScaleDiscount:  0.1,
+	}
+}
+
+// Cost implements CostModel. Pipes that are not in net are ignored.
+func (m *TableCostModel) Cost(net *graph.Network, pipes []graph.ID) float64 {
+	var works, extras, length float64
+	for _, id := range pipes {
+		node, ok := net.Nodes[id]
+		if !ok {
+			continue
+		}
+		length += node.Length
+		for _, r := range m.Rates {
+			if r.matches(node) {
+				works += r.PerMetre * node.Length
+				break
+			}
+		}
+		for _, s := range m.Surcharges {
+			if s.matches(node) {
+				extras += s.PerMetre*node.Length + s.PerPipe
+			}
+		}
+	}
+
+	// Metres beyond the threshold get the discount, at the average rate
+	if m.ScaleThreshold > 0 && m.ScaleDiscount > 0 && length > m.ScaleThreshold {
+		works -= works * m.ScaleDiscount * (length - m.ScaleThreshold) / length
+	}
+	return m.Mobilisation + works + extras
+}
@@ -0,0 +1,79 @@
+package planner
+
+import (
+	"math"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestDefaultCostModel(t *testing.T) {
+	net := graph.New()
+	net.AddNode(&graph.Node{ID: 1, Length: 100})                                                          // unknown diameter, €500/m
+	net.AddNode(&graph.Node{ID: 2, Length: 100, Diameter: 150})                                           // €450/m
+	net.AddNode(&graph.Node{ID: 3, Length: 100, Diameter: 200, Material: "asbestos cement"})              // €600/m + €100/m
+	net.AddNode(&graph.Node{ID: 4, Length: 100, Diameter: 800, Attrs: map[string]string{"road": "main"}}) // €1,200/m + €5,000
+	net.AddNode(&graph.Node{ID: 5, Length: 900})                                                          // €500/m
+	model := DefaultCostModel()
+
+	tests := []struct {
+		name     string
+		pipes    []graph.ID
+		expected float64
+	}{
+		{name: "mobilisation only", expected: 10000},
+		{name: "unknown diameter", pipes: []graph.ID{1}, expected: 10000 + 50000},
+		{name: "diameter band", pipes: []graph.ID{2}, expected: 10000 + 45000},
+		{name: "material surcharge", pipes: []graph.ID{3}, expected: 10000 + 60000 + 10000},
+		{name: "road surcharge", pipes: []graph.ID{4}, expected: 10000 + 120000 + 5000},
+		{name: "unknown pipe ignored", pipes: []graph.ID{1, 99}, expected: 10000 + 50000},
+		{
+			// 1,000m of works for €500,000 is exactly at the threshold
+			name:     "at scale threshold",
+			pipes:    []graph.ID{1, 5},
+			expected: 10000 + 500000,
+		},
+		{
+			// 1,100m of works for €545,000; the last 100m get 10% off at the
+			// average rate: 545,000 * 0.1 * 100/1,100 = 4,954.55
+			name:     "economies of scale",
+			pipes:    []graph.ID{1, 2, 5},
+			expected: 10000 + 545000 - 545000*0.1*100/1100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.Cost(net, tt.pipes); math.Abs(got-tt.expected) > 1e-6 {
+				t.Errorf("expected cost %.2f, got %.2f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTableCostModelRates(t *testing.T) {
+	pvc := &graph.Node{ID: 1, Length: 10, Material: "PVC", Diameter: 100}
+	iron := &graph.Node{ID: 2, Length: 10, Material: "cast iron", Diameter: 100}
+	net := graph.New()
+	net.AddNode(pvc)
+	net.AddNode(iron)
+
+	// Material rows come before the general band; the first match wins
+	model := &TableCostModel{Rates: []Rate{
+		{Material: "cast iron", PerMetre: 700},
+		{MaxDiameter: 200, PerMetre: 300},
+		{MaxDiameter: 200, PerMetre: 999},
+	}}
+	if got := model.Cost(net, []graph.ID{1}); got != 3000 {
+		t.Errorf("expected PVC cost 3000, got %v", got)
+	}
+	if got := model.Cost(net, []graph.ID{2}); got != 7000 {
+		t.Errorf("expected cast iron cost 7000, got %v", got)
+	}
+
+	// Without a matching row a pipe costs nothing per metre
+	pvc.Diameter = 0
+	if got := model.Cost(net, []graph.ID{1}); got != 0 {
+		t.Errorf("expected no cost for a pipe without a rate, got %v", got)
+	}
+}
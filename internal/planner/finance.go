@@ -0,0 +1,132 @@
+package planner
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrNoIRR is returned by IRR when the cash flows have no internal rate of
+// return, e.g. because they never change sign.
+var ErrNoIRR = errors.New("planner: cash flows have no internal rate of return")
+
+// Finance holds the assumptions projects are appraised with. Rates are
+// annual fractions, e.g. 0.04 for 4%.
+type Finance struct {
+	DiscountRate  float64 // Discount rate for future cash flows
+	InflationRate float64 // Yearly escalation of failure costs
+	Horizon       int     // Analysis horizon in years (default 50)
+	AssetLife     int     // Service life of renewed pipes for annuitised capex (default Horizon)
+}
+
+// DefaultFinance returns the assumptions used by the demos: 4% discount
+// rate, 2% inflation, a 50 year horizon and an 80 year asset life.
+func DefaultFinance() Finance {
+	return Finance{DiscountRate: 0.04, InflationRate: 0.02, Horizon: 50, AssetLife: 80}
+}
+
+func (f Finance) withDefaults() Finance {
+	if f.Horizon <= 0 {
+		f.Horizon = 50
+	}
+	if f.AssetLife <= 0 {
+		f.AssetLife = f.Horizon
+	}
+	return f
+}
+
+// CashFlows returns the yearly cash flows of a project that costs cost now
+// and removes bre of yearly expected failure cost in today's prices: -cost
+// in year 0, then bre escalated by InflationRate in years 1 to Horizon.
+func (f Finance) CashFlows(cost, bre float64) []float64 {
+	f = f.withDefaults()
+	flows := make([]float64, f.Horizon+1)
+	flows[0] = -cost
+	for t := 1; t <= f.Horizon; t++ {
+		flows[t] = bre * math.Pow(1+f.InflationRate, float64(t))
+	}
+	return flows
+}
+
+// DiscountFactor returns the present value of €1 paid after the given
+// number of years.
+func DiscountFactor(rate float64, year int) float64 {
+	return math.Pow(1+rate, -float64(year))
+}
+
+// NPV returns the net present value of cash flows, where flows[t] is paid
+// at the end of year t and flows[0] is paid now.
+func NPV(rate float64, flows []float64) float64 {
+	var npv float64
+	for t, cf := range flows {
+		npv += cf * DiscountFactor(rate, t)
+	}
+	return npv
+}
+
+// IRR returns the discount rate at which the NPV of flows is zero. It
+// expects conventional flows, costs first and returns after, and finds the
+// rate by bisection, searching upwards from -99%. Flows that are all zero
+// have a zero NPV at every rate and so no IRR.
+func IRR(flows []float64) (float64, error) {
+	allZero := true
+	for _, cf := range flows {
+		if cf != 0 {
+			allZero = false
+			break
+		}
+	}
+	if allZero {
+		return math.NaN(), ErrNoIRR
+	}
+
+	lo, hi := -0.99, 1.0
+	npvLo := NPV(lo, flows)
+	for NPV(hi, flows)*npvLo > 0 {
+		if hi >= 1e4 {
+			return math.NaN(), ErrNoIRR
+		}
+		hi *= 2
+	}
+	if npvLo == 0 {
+		return lo, nil
+	}
+	for i := 0; i < 200 && hi-lo > 1e-12; i++ {
+		mid := (lo + hi) / 2
+		if npv := NPV(mid, flows); npv*npvLo > 0 {
+			lo, npvLo = mid, npv
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, nil
+}
+
+// Annuity returns the equal yearly payment over years that has the same
+// present value as capex now.
+func Annuity(capex, rate float64, years int) float64 {
+	if years <= 0 {
+		return capex
+	}
+	if rate == 0 {
+		return capex / float64(years)
+	}
+	return capex * rate / (1 - math.Pow(1+rate, -float64(years)))
+}
+
+// Payback returns the discounted payback period of flows in years: when the
+// cumulative discounted cash flow turns non-negative, interpolated within
+// the year. It is +Inf if that never happens.
+func Payback(rate float64, flows []float64) float64 {
+	var cum float64
+	for t, cf := range flows {
+		pv := cf * DiscountFactor(rate, t)
+		if cum < 0 && cum+pv >= 0 {
+			return float64(t-1) + -cum/pv
+		}
+		cum += pv
+		if t == 0 && cum >= 0 {
+			return 0
+		}
+	}
+	return math.Inf(1)
+}
@@ -0,0 +1,125 @@
+package planner
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNPV(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		flows    []float64
+		expected float64
+	}{
+		{name: "no flows", rate: 0.05},
+		{name: "zero rate sums", rate: 0, flows: []float64{-100, 60, 60}, expected: 20},
+		{
+			// -100 + 110/1.1 + 121/1.21
+			name:     "discounted",
+			rate:     0.1,
+			flows:    []float64{-100, 110, 121},
+			expected: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NPV(tt.rate, tt.flows); math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected NPV %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIRR(t *testing.T) {
+	tests := []struct {
+		name     string
+		flows    []float64
+		expected float64
+	}{
+		{name: "one year", flows: []float64{-100, 110}, expected: 0.1},
+		{name: "two years", flows: []float64{-100, 0, 121}, expected: 0.1},
+		{name: "loss", flows: []float64{-100, 50}, expected: -0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IRR(tt.flows)
+			if err != nil {
+				t.Fatalf("IRR: %v", err)
+			}
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected IRR %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	noIRR := []struct {
+		name  string
+		flows []float64
+	}{
+		{name: "never positive", flows: []float64{-100, -10}},
+		{name: "all zero", flows: []float64{0, 0, 0}},
+		{name: "no flows"},
+	}
+	for _, tt := range noIRR {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := IRR(tt.flows); !errors.Is(err, ErrNoIRR) || !math.IsNaN(got) {
+				t.Errorf("expected ErrNoIRR and NaN, got %v, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestAnnuity(t *testing.T) {
+	// 1,000 at 10% over 2 years: 1,000 * 0.1 / (1 - 1.1^-2) = 576.19
+	if got := Annuity(1000, 0.1, 2); math.Abs(got-576.1904761904) > 1e-6 {
+		t.Errorf("expected annuity 576.19, got %v", got)
+	}
+	if got := Annuity(1000, 0, 4); got != 250 {
+		t.Errorf("expected annuity 250 without interest, got %v", got)
+	}
+}
+
+func TestPayback(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		flows    []float64
+		expected float64
+	}{
+		{name: "paid back within year 2", rate: 0, flows: []float64{-100, 60, 80}, expected: 1.5},
+		{name: "exactly at year 1", rate: 0, flows: []float64{-100, 100}, expected: 1},
+		{name: "discounted flows", rate: 0.1, flows: []float64{-100, 110, 121}, expected: 1},
+		{name: "nothing to pay back", rate: 0, flows: []float64{0, 10}, expected: 0},
+		{name: "never", rate: 0.1, flows: []float64{-100, 50, 50}, expected: math.Inf(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Payback(tt.rate, tt.flows); math.Abs(got-tt.expected) > 1e-9 && got != tt.expected {
+				t.Errorf("expected payback %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFinanceCashFlows(t *testing.T) {
+	fin := Finance{InflationRate: 0.1, Horizon: 2}
+	expected := []float64{-500, 110, 121}
+	got := fin.CashFlows(500, 100)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d cash flows, got %d", len(expected), len(got))
+	}
+	for i := range expected {
+		if math.Abs(got[i]-expected[i]) > 1e-9 {
+			t.Errorf("year %d: expected %v, got %v", i, expected[i], got[i])
+		}
+	}
+
+	if n := len(Finance{}.CashFlows(1, 1)); n != 51 {
+		t.Errorf("expected the default 50 year horizon, got %d flows", n)
+	}
+}
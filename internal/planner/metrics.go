@@ -0,0 +1,94 @@
+package planner
+
+import (
+	"math"
+	"sort"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// ProjectMetrics appraises a cluster as a renewal project, or a set of
+// clusters as a portfolio. BRE is the yearly expected failure cost the
+// project removes; the financial metrics assume it is removed for the whole
+// horizon of the Finance they were computed with.
+type ProjectMetrics struct {
+	Cluster
+	AvgRisk     float64 // Mean LoF of the pipes
+	RiskDensity float64 // LoF per km
+	Cost        float64 // Capital cost in € from the CostModel
+	BRE         float64 // ΣLoF × CoF over the pipes, in € per year
+	ROI         float64 // BRE / Cost
+
+	PVBenefit   float64 // Present value of the avoided failure costs over the horizon
+	NPV         float64 // PVBenefit - Cost
+	BCR         float64 // Benefit/cost ratio, PVBenefit / Cost
+	IRR         float64 // Internal rate of return (NaN if there is none)
+	Payback     float64 // Discounted payback period in years (+Inf if beyond the horizon)
+	AnnualCapex float64 // Cost as a yearly annuity over the asset life
+}
+
+// NewProjectMetrics prices the cluster with costs, sums its BRE with cof and
+// appraises it with fin.
+func NewProjectMetrics(net *graph.Network, cluster Cluster, costs CostModel, cof CoFModel, fin Finance) ProjectMetrics {
+	m := ProjectMetrics{Cluster: cluster}
+	if len(cluster.Nodes) > 0 {
+		m.AvgRisk = cluster.Score / float64(len(cluster.Nodes))
+	}
+	if cluster.Length > 0 {
+		m.RiskDensity = cluster.Score / (cluster.Length / 1000)
+	}
+	m.appraise(costs.Cost(net, cluster.Nodes), BRE(net, cluster.Nodes, cof), fin)
+	return m
+}
+
+// CombineMetrics appraises projects as one portfolio: pipes, scores,
+// lengths, costs and BRE add up and the financial metrics are computed on
+// the combined cash flows. The result has ID 0 and the seed of the first
+// project.
+func CombineMetrics(projects []ProjectMetrics, fin Finance) ProjectMetrics {
+	var total ProjectMetrics
+	var cost, bre float64
+	for i, p := range projects {
+		if i == 0 {
+			total.Seed = p.Seed
+		}
+		total.Nodes = append(total.Nodes, p.Nodes...)
+		total.Score += p.Score
+		total.Length += p.Length
+		cost += p.Cost
+		bre += p.BRE
+	}
+	sort.Slice(total.Nodes, func(i, j int) bool { return total.Nodes[i] < total.Nodes[j] })
+	if len(total.Nodes) > 0 {
+		total.AvgRisk = total.Score / float64(len(total.Nodes))
+	}
+	if total.Length > 0 {
+		total.RiskDensity = total.Score / (total.Length / 1000)
+	}
+	total.appraise(cost, bre, fin)
+	return total
+}
+
+// appraise sets the cost, BRE and the metrics derived from them.
+func (m *ProjectMetrics) appraise(cost, bre float64, fin Finance) {
+	fin = fin.withDefaults()
+	m.Cost, m.BRE = cost, bre
+	flows := fin.CashFlows(cost, bre)
+	m.NPV = NPV(fin.DiscountRate, flows)
+	m.PVBenefit = m.NPV + cost
+	m.Payback = Payback(fin.DiscountRate, flows)
+	m.AnnualCapex = Annuity(cost, fin.DiscountRate, fin.AssetLife)
+	m.IRR, _ = IRR(flows) // NaN without an IRR
+	if cost > 0 {
+		m.ROI = bre / cost
+		m.BCR = m.PVBenefit / cost
+	} else {
+		m.ROI, m.BCR = math.Inf(1), math.Inf(1)
+	}
+}
+
+// Candidate returns the project as a portfolio candidate with its BRE as
+// the benefit.
+func (m ProjectMetrics) Candidate() Candidate {
+	return Candidate{Cluster: m.Cluster, Cost: m.Cost, Benefit: m.BRE}
+}
@@ -0,0 +1,72 @@
+package planner
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestNewProjectMetrics(t *testing.T) {
+	net := graph.New()
+	net.AddNode(&graph.Node{ID: 1, Score: 0.5, Length: 100})
+	net.AddNode(&graph.Node{ID: 2, Score: 0.3, Length: 300})
+	cluster := Cluster{ID: 1, Seed: 1, Nodes: []graph.ID{1, 2}, Score: 0.8, Length: 400}
+
+	// Flat €100/m and a fixed CoF of €10,000 per failure: cost 40,000 and
+	// BRE 0.8 * 10,000 = 8,000 a year
+	costs := &TableCostModel{Rates: []Rate{{PerMetre: 100}}}
+	cof := &FailureModeModel{Modes: []FailureMode{{Probability: 1, Cost: 10000}}}
+	fin := Finance{DiscountRate: 0.05, Horizon: 10, AssetLife: 20}
+
+	m := NewProjectMetrics(net, cluster, costs, cof, fin)
+	if m.Cost != 40000 || math.Abs(m.BRE-8000) > 1e-9 {
+		t.Fatalf("expected cost 40000 and BRE 8000, got %v and %v", m.Cost, m.BRE)
+	}
+	if m.AvgRisk != 0.4 || m.RiskDensity != 2 || m.ROI != 0.2 {
+		t.Errorf("expected avg risk 0.4, density 2 and ROI 0.2, got %v, %v and %v", m.AvgRisk, m.RiskDensity, m.ROI)
+	}
+
+	// 8,000 a year for 10 years at 5% is worth 8,000 * 7.7217 = 61,773.47
+	pv := 8000 * (1 - math.Pow(1.05, -10)) / 0.05
+	if math.Abs(m.PVBenefit-pv) > 1e-6 || math.Abs(m.NPV-(pv-40000)) > 1e-6 || math.Abs(m.BCR-pv/40000) > 1e-9 {
+		t.Errorf("expected PV %.2f, NPV %.2f and BCR %.3f, got %.2f, %.2f and %.3f",
+			pv, pv-40000, pv/40000, m.PVBenefit, m.NPV, m.BCR)
+	}
+	if math.Abs(NPV(m.IRR, fin.CashFlows(m.Cost, m.BRE))) > 1e-6 {
+		t.Errorf("expected a zero NPV at the IRR %v", m.IRR)
+	}
+	if m.Payback <= 5 || m.Payback >= 6 {
+		t.Errorf("expected payback in the sixth year, got %v", m.Payback)
+	}
+	if math.Abs(m.AnnualCapex-Annuity(40000, 0.05, 20)) > 1e-9 {
+		t.Errorf("expected capex annuitised over the asset life, got %v", m.AnnualCapex)
+	}
+	if c := m.Candidate(); c.Cost != m.Cost || c.Benefit != m.BRE || !reflect.DeepEqual(c.Cluster, cluster) {
+		t.Errorf("expected a candidate with the project's cost and BRE, got %+v", c)
+	}
+}
+
+func TestCombineMetrics(t *testing.T) {
+	fin := Finance{DiscountRate: 0.05, Horizon: 10}
+	a := ProjectMetrics{Cluster: Cluster{Seed: 3, Nodes: []graph.ID{3, 4}, Score: 1, Length: 200}}
+	a.appraise(10000, 2000, fin)
+	b := ProjectMetrics{Cluster: Cluster{Seed: 1, Nodes: []graph.ID{1}, Score: 0.5, Length: 300}}
+	b.appraise(30000, 1000, fin)
+
+	total := CombineMetrics([]ProjectMetrics{a, b}, fin)
+	if !reflect.DeepEqual(total.Nodes, []graph.ID{1, 3, 4}) || total.Seed != 3 {
+		t.Errorf("expected pipes [1 3 4] and seed 3, got %v and %d", total.Nodes, total.Seed)
+	}
+	if total.Cost != 40000 || total.BRE != 3000 || total.RiskDensity != 3 {
+		t.Errorf("expected cost 40000, BRE 3000 and density 3, got %v, %v and %v", total.Cost, total.BRE, total.RiskDensity)
+	}
+	// Cash flows add up, so NPVs do too
+	if math.Abs(total.NPV-(a.NPV+b.NPV)) > 1e-6 {
+		t.Errorf("expected portfolio NPV %v, got %v", a.NPV+b.NPV, total.NPV)
+	}
+	if total.IRR <= b.IRR || total.IRR >= a.IRR {
+		t.Errorf("expected the portfolio IRR between %v and %v, got %v", b.IRR, a.IRR, total.IRR)
+	}
+}
@@ -1,16 +1,277 @@
 package planner
 
-import "github.com/thanos-fil/planner-demo-go/internal/graph"
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+	"github.com/thanos-fil/planner-demo-go/internal/neighbourhood"
+)
 
 // Cluster represents a cluster of connected nodes for a project
 type Cluster struct {
-	ID    int        // Project ID
-	Nodes []graph.ID // Pipes in this cluster
-	Score float64    // Total risk/score for this cluster
+	ID     int        // Project ID
+	Seed   graph.ID   // Pipe the cluster was grown from
+	Nodes  []graph.ID // Pipes in this cluster
+	Score  float64    // Total risk/score for this cluster
+	Length float64    // Total pipe length of this cluster
+}
+
+// CreateCandidateClusters grows one cluster per seed pipe and returns the
+// clusters ranked by total score, highest first, numbered from 1.
+//
+// Seeds come from cfg.SeedStrategy; the default takes them in descending
+// score order (ties broken by ID). Pipes without any risk are never used as
+// seeds. Each cluster only contains pipes
+// whose shortest path from the seed is at most MaxLength*LongestPathFraction.
+// Within that region the cluster grows over connected pipes, always taking
+// the frontier pipe with the highest score, until its total length reaches
+// MaxLength; the last pipe added may take it up to MaxLength*OvershootFactor.
+// A pipe belongs to at most one cluster and at most TargetCount clusters are
+// created. With cfg.AllowOverlap clusters grow independently of each other
+// and may share pipes, which gives overlapping candidates for
+// SelectPortfolio; seeds that would repeat an earlier cluster are skipped.
+//
+// Excluded pipes are never part of a cluster and clusters cannot grow
+// through them. Must-include pipes seed clusters before any other pipe,
+// whatever their score, and are taken first when a cluster grows, so pipes
+// scheduled together tend to end up together. A must-include pipe is always
+// placed, even if that takes more than TargetCount clusters or a cluster
+// of just that pipe beyond the length limits; without a positive MaxLength
+// only the must-include pipes are planned, each on its own. An unknown must-include pipe,
+// or one that is also excluded, is an error.
+//
+// With cfg.Strict set the network is validated first and a
+// *graph.ValidationError is returned if it has error-level issues.
+func CreateCandidateClusters(net *graph.Network, cfg UserCfg) ([]Cluster, error) {
+	if net == nil {
+		return nil, errors.New("planner: nil network")
+	}
+	if cfg.Strict {
+		if err := net.Validate().Err(); err != nil {
+			return nil, err
+		}
+	}
+	cfg = cfg.withDefaults()
+	cons, err := newConstraints(net, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxLength <= 0 && len(cons.include) == 0 {
+		return nil, nil
+	}
+
+	claimed := make(map[graph.ID]bool)
+	seen := make(map[string]bool) // node sets of overlapping clusters
+	var clusters []Cluster
+
+	seeds, err := seedOrder(net, cfg, cons)
+	if err != nil {
+		return nil, err
+	}
+	for _, seed := range seeds {
+		if claimed[seed] {
+			continue
+		}
+		if !cons.include[seed] && (cfg.MaxLength <= 0 || (cfg.TargetCount > 0 && len(clusters) >= cfg.TargetCount)) {
+			break // must-include seeds come first
+		}
+
+		cluster, ok := growCluster(net, seed, cfg, cons, claimed)
+		if !ok {
+			continue
+		}
+		if cfg.AllowOverlap {
+			key := fmt.Sprint(cluster.Nodes)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		} else {
+			for _, id := range cluster.Nodes {
+				claimed[id] = true
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	rankClusters(clusters)
+	return clusters, nil
+}
+
+// seedOrder returns the must-include pipes, highest score first and lowest
+// ID first among equal scores, followed by the seeds of cfg.SeedStrategy
+// that are neither excluded nor must-include.
+func seedOrder(net *graph.Network, cfg UserCfg, cons constraints) ([]graph.ID, error) {
+	var seeds []graph.ID
+	for id := range cons.include {
+		seeds = append(seeds, id)
+	}
+	sort.Slice(seeds, func(i, j int) bool {
+		a, b := net.Nodes[seeds[i]], net.Nodes[seeds[j]]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.ID < b.ID
+	})
+
+	picked, err := cfg.SeedStrategy.Seeds(net, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range picked {
+		if _, ok := net.Nodes[id]; !ok {
+			return nil, fmt.Errorf("planner: seed strategy %q picked unknown pipe %d", cfg.SeedStrategy.Name(), id)
+		}
+		if !cons.include[id] && !cons.excluded(id) {
+			seeds = append(seeds, id)
+		}
+	}
+	return seeds, nil
+}
+
+// growCluster grows a single cluster from seed, skipping pipes that are
+// already claimed by another cluster. It reports false if not even the seed
+// fits within the configured limits, unless the seed must be included.
+func growCluster(net *graph.Network, seed graph.ID, cfg UserCfg, cons constraints, claimed map[graph.ID]bool) (Cluster, bool) {
+	hardCap := cfg.MaxLength * cfg.OvershootFactor
+	seeds := []neighbourhood.Seed{{Root: seed, Budget: cfg.MaxLength * cfg.LongestPathFraction}}
+	region := neighbourhood.SearchWith(net, seeds, neighbourhood.Options{Exclude: cons.exclude})
+	if !cons.include[seed] && (!region.Contains(seed) || net.Nodes[seed].Length > hardCap) {
+		return Cluster{}, false
+	}
+
+	cluster := Cluster{Seed: seed}
+	inCluster := make(map[graph.ID]bool)
+	frontier := make(frontierQueue, 0, 16)
+
+	add := func(id graph.ID) {
+		node := net.Nodes[id]
+		inCluster[id] = true
+		cluster.Nodes = append(cluster.Nodes, id)
+		cluster.Score += node.Score
+		cluster.Length += node.Length
+
+		for _, nbr := range net.Edges[id] {
+			if !region.Contains(nbr) || inCluster[nbr] || claimed[nbr] {
+				continue
+			}
+			heap.Push(&frontier, frontierItem{node: net.Nodes[nbr], forced: cons.include[nbr]})
+		}
+	}
+
+	add(seed)
+	for cluster.Length < cfg.MaxLength && frontier.Len() > 0 {
+		node := heap.Pop(&frontier).(frontierItem).node
+		if inCluster[node.ID] {
+			continue // reached through more than one cluster pipe
+		}
+		if cluster.Length+node.Length > hardCap {
+			continue // would overshoot too far, try a shorter pipe
+		}
+		add(node.ID)
+	}
+
+	sort.Slice(cluster.Nodes, func(i, j int) bool { return cluster.Nodes[i] < cluster.Nodes[j] })
+	return cluster, true
+}
+
+// rankClusters sorts clusters by total score (then shorter length, then seed
+// ID) and numbers them from 1 in that order.
+func rankClusters(clusters []Cluster) {
+	sort.Slice(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Length != b.Length {
+			return a.Length < b.Length
+		}
+		return a.Seed < b.Seed
+	})
+	for i := range clusters {
+		clusters[i].ID = i + 1
+	}
+}
+
+// frontierItem is a candidate pipe for growing a cluster.
+type frontierItem struct {
+	node   *graph.Node
+	forced bool // must-include pipe
+}
+
+// frontierQueue is a max-heap of candidate pipes: must-include pipes first,
+// then by score, with the lowest ID first among equal scores.
+type frontierQueue []frontierItem
+
+func (q frontierQueue) Len() int { return len(q) }
+
+func (q frontierQueue) Less(i, j int) bool {
+	a, b := q[i], q[j]
+	if a.forced != b.forced {
+		return a.forced
+	}
+	if a.node.Score != b.node.Score {
+		return a.node.Score > b.node.Score
+	}
+	return a.node.ID < b.node.ID
+}
+
+func (q frontierQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *frontierQueue) Push(x interface{}) { *q = append(*q, x.(frontierItem)) }
+
+func (q *frontierQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = frontierItem{} // avoid memory leak
+	*q = old[:n-1]
+	return item
+}
+
+// ComponentClusters holds the clusters planned on one connected component.
+type ComponentClusters struct {
+	Component graph.Component
+	Clusters  []Cluster
 }
 
-func CreateCandidateClusters(net *graph.Network, cfg UserCfg) []Cluster {
-	// Implementation of cluster creation logic based on the network and user configuration
-	// This function will analyze the network and create clusters based on the provided configuration
-	return nil // Placeholder return, actual implementation will return a slice of Cluster
+// CreateClustersByComponent runs CreateCandidateClusters separately on every
+// connected component of the network, largest component first. TargetCount
+// applies per component and cluster IDs are numbered from 1 within each
+// component. To plan on a single component instead, pass
+// net.Subnetwork(component.Nodes) to CreateCandidateClusters.
+func CreateClustersByComponent(net *graph.Network, cfg UserCfg) ([]ComponentClusters, error) {
+	if net == nil {
+		return nil, errors.New("planner: nil network")
+	}
+	if cfg.Strict {
+		if err := net.Validate().Err(); err != nil {
+			return nil, err
+		}
+		cfg.Strict = false // already checked for the whole network
+	}
+	cons, err := newConstraints(net, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ComponentClusters
+	for _, comp := range net.Components() {
+		compCfg := cfg
+		compCfg.Include = nil
+		for _, id := range comp.Nodes {
+			if cons.include[id] {
+				compCfg.Include = append(compCfg.Include, id)
+			}
+		}
+		clusters, err := CreateCandidateClusters(net.Subnetwork(comp.Nodes), compCfg)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ComponentClusters{Component: comp, Clusters: clusters})
+	}
+	return result, nil
 }
@@ -0,0 +1,266 @@
+package planner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func TestCreateCandidateClusters(t *testing.T) {
+	net := MockExampleNetwork()
+
+	tests := []struct {
+		name     string
+		cfg      UserCfg
+		expected [][]graph.ID
+	}{
+		{
+			name: "zero config creates nothing",
+			cfg:  UserCfg{},
+		},
+		{
+			// Seeds in score order: 16, 9, 1, 5, 11, 12
+			// - 16 grows into 12 (total 2.5), 11 would be 3.5 from the seed
+			// - 9 grows into 5 and 1 (total 3.0)
+			// - 11 is left on its own because 12 is already claimed
+			name: "all clusters on mock network",
+			cfg:  UserCfg{MaxLength: 3.0},
+			expected: [][]graph.ID{
+				{1, 5, 9},
+				{12, 16},
+				{11},
+			},
+		},
+		{
+			name: "target count limits seeds",
+			cfg:  UserCfg{TargetCount: 2, MaxLength: 3.0},
+			expected: [][]graph.ID{
+				{1, 5, 9},
+				{12, 16},
+			},
+		},
+		{
+			// Longest path capped at 1.5: every seed is left on its own
+			name: "longest path fraction",
+			cfg:  UserCfg{TargetCount: 3, MaxLength: 3.0, LongestPathFraction: 0.5},
+			expected: [][]graph.ID{
+				{16},
+				{9},
+				{1},
+			},
+		},
+		{
+			// Clusters grow as if alone; 1, 5 and 12 would repeat an earlier
+			// cluster and are skipped, 11 shares 12 with the cluster of 16
+			name: "overlapping clusters",
+			cfg:  UserCfg{MaxLength: 3.0, AllowOverlap: true},
+			expected: [][]graph.ID{
+				{1, 5, 9},
+				{12, 16},
+				{11, 12},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters, err := CreateCandidateClusters(net, tt.cfg)
+			if err != nil {
+				t.Fatalf("CreateCandidateClusters: %v", err)
+			}
+
+			if len(clusters) != len(tt.expected) {
+				t.Fatalf("expected %d clusters, got %d: %v", len(tt.expected), len(clusters), clusters)
+			}
+			for i, c := range clusters {
+				if c.ID != i+1 {
+					t.Errorf("cluster %d: expected ID %d, got %d", i, i+1, c.ID)
+				}
+				if !reflect.DeepEqual(c.Nodes, tt.expected[i]) {
+					t.Errorf("cluster %d: expected nodes %v, got %v", c.ID, tt.expected[i], c.Nodes)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateCandidateClustersOvershoot(t *testing.T) {
+	// Star network: centre 0 with leaves 1, 2 and 3, all of length 1.0
+	net := graph.New()
+	net.AddNode(&graph.Node{ID: 0, Score: 0.9, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 1, Score: 0.5, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 2, Score: 0.4, Length: 1.0})
+	net.AddNode(&graph.Node{ID: 3, Score: 0.3, Length: 1.0})
+	net.AddUndirectedEdge(0, 1)
+	net.AddUndirectedEdge(0, 2)
+	net.AddUndirectedEdge(0, 3)
+
+	tests := []struct {
+		name      string
+		overshoot float64
+		expected  []graph.ID
+	}{
+		{
+			// 0 + 1 = 2.0, adding 2 would give 3.0 > 2.5
+			name:      "no overshoot",
+			overshoot: 1.0,
+			expected:  []graph.ID{0, 1},
+		},
+		{
+			// 3.0 <= 2.5*1.2, growth stops once 2.5 is reached
+			name:      "overshoot allows one more pipe",
+			overshoot: 1.2,
+			expected:  []graph.ID{0, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters, err := CreateCandidateClusters(net, UserCfg{
+				TargetCount:     1,
+				MaxLength:       2.5,
+				OvershootFactor: tt.overshoot,
+			})
+			if err != nil {
+				t.Fatalf("CreateCandidateClusters: %v", err)
+			}
+			if len(clusters) != 1 {
+				t.Fatalf("expected 1 cluster, got %d", len(clusters))
+			}
+			if !reflect.DeepEqual(clusters[0].Nodes, tt.expected) {
+				t.Errorf("expected nodes %v, got %v", tt.expected, clusters[0].Nodes)
+			}
+		})
+	}
+}
+
+func TestCreateCandidateClustersDeterministic(t *testing.T) {
+	net := MockExampleNetwork()
+	cfg := UserCfg{TargetCount: 10, MaxLength: 3.0, OvershootFactor: 1.2, LongestPathFraction: 0.8}
+
+	first, _ := CreateCandidateClusters(net, cfg)
+	for i := 0; i < 20; i++ {
+		if got, _ := CreateCandidateClusters(net, cfg); !reflect.DeepEqual(first, got) {
+			t.Fatalf("run %d differs: %v vs %v", i, first, got)
+		}
+	}
+}
+
+func TestCreateCandidateClustersStrict(t *testing.T) {
+	net := MockExampleNetwork()
+	net.AddUndirectedEdge(9, 99) // 99 does not exist
+
+	// Lenient mode plans around the dangling edge
+	clusters, err := CreateCandidateClusters(net, UserCfg{MaxLength: 3.0})
+	if err != nil {
+		t.Fatalf("expected no error in lenient mode, got %v", err)
+	}
+	if len(clusters) != 3 {
+		t.Errorf("expected 3 clusters, got %d", len(clusters))
+	}
+
+	_, err = CreateCandidateClusters(net, UserCfg{MaxLength: 3.0, Strict: true})
+	var verr *graph.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *graph.ValidationError, got %v", err)
+	}
+	if verr.Report.Count(graph.DanglingEdge) != 1 {
+		t.Errorf("expected 1 dangling edge, got %v", verr.Report.Issues)
+	}
+}
+
+func TestCreateClustersByComponent(t *testing.T) {
+	net := MockExampleNetwork()
+
+	// Components with risk: {1, 5, 9} and {11, 12, 16}; one cluster each
+	result, err := CreateClustersByComponent(net, UserCfg{TargetCount: 1, MaxLength: 3.0})
+	if err != nil {
+		t.Fatalf("CreateClustersByComponent: %v", err)
+	}
+
+	var planned [][]graph.ID
+	for _, cc := range result {
+		for _, c := range cc.Clusters {
+			if c.ID != 1 {
+				t.Errorf("expected cluster IDs to restart per component, got %d", c.ID)
+			}
+			planned = append(planned, c.Nodes)
+		}
+	}
+	expected := [][]graph.ID{{1, 5, 9}, {12, 16}}
+	if !reflect.DeepEqual(planned, expected) {
+		t.Errorf("expected clusters %v, got %v", expected, planned)
+	}
+}
+
+func TestCreateCandidateClustersConstraints(t *testing.T) {
+	net := MockExampleNetwork()
+
+	tests := []struct {
+		name     string
+		cfg      UserCfg
+		expected [][]graph.ID
+	}{
+		{
+			// 12 blocks the way from 16 to 11
+			name:     "exclude blocks growth",
+			cfg:      UserCfg{MaxLength: 3.0, Exclude: []graph.ID{12}},
+			expected: [][]graph.ID{{1, 5, 9}, {16}, {11}},
+		},
+		{
+			name:     "excluded pipe splits a chain",
+			cfg:      UserCfg{MaxLength: 3.0, Exclude: []graph.ID{5}},
+			expected: [][]graph.ID{{12, 16}, {9}, {1}, {11}},
+		},
+		{
+			// 11 seeds first and takes 12 before 16 can
+			name:     "must-include seeds first",
+			cfg:      UserCfg{MaxLength: 3.0, Include: []graph.ID{11}},
+			expected: [][]graph.ID{{1, 5, 9}, {16}, {11, 12}},
+		},
+		{
+			// 0 has no risk and no neighbours but is placed anyway
+			name:     "must-include without risk",
+			cfg:      UserCfg{MaxLength: 3.0, Include: []graph.ID{0}},
+			expected: [][]graph.ID{{1, 5, 9}, {12, 16}, {11}, {0}},
+		},
+		{
+			// Nothing else is planned without a length budget
+			name:     "must-include without max length",
+			cfg:      UserCfg{Include: []graph.ID{9, 16}},
+			expected: [][]graph.ID{{16}, {9}},
+		},
+		{
+			name:     "must-include ignores target count",
+			cfg:      UserCfg{TargetCount: 1, MaxLength: 3.0, Include: []graph.ID{0, 3}},
+			expected: [][]graph.ID{{0}, {3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters, err := CreateCandidateClusters(net, tt.cfg)
+			if err != nil {
+				t.Fatalf("CreateCandidateClusters: %v", err)
+			}
+			var got [][]graph.ID
+			for _, c := range clusters {
+				got = append(got, c.Nodes)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected clusters %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	for _, cfg := range []UserCfg{
+		{MaxLength: 3.0, Include: []graph.ID{99}},
+		{MaxLength: 3.0, Include: []graph.ID{5}, Exclude: []graph.ID{5}},
+	} {
+		if _, err := CreateCandidateClusters(net, cfg); err == nil {
+			t.Errorf("expected an error for include %v, exclude %v", cfg.Include, cfg.Exclude)
+		}
+	}
+}
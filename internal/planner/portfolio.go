@@ -0,0 +1,316 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// Candidate is a cluster that can be put in a portfolio, with what it costs
+// and how much BRE (business risk exposure) it removes.
+type Candidate struct {
+	Cluster
+	Cost    float64 // Cost of the project in €
+	Benefit float64 // BRE reduction in €
+}
+
+// PortfolioMethod selects how SelectPortfolio searches.
+type PortfolioMethod int
+
+const (
+	Auto   PortfolioMethod = iota // Exact for small inputs, Greedy otherwise
+	Exact                         // Branch-and-bound
+	Greedy                        // Best benefit per € first
+)
+
+func (m PortfolioMethod) String() string {
+	switch m {
+	case Auto:
+		return "auto"
+	case Exact:
+		return "exact"
+	case Greedy:
+		return "greedy"
+	default:
+		return fmt.Sprintf("PortfolioMethod(%d)", int(m))
+	}
+}
+
+// MaxExactCandidates is the most candidates the exact search accepts.
+const MaxExactCandidates = 64
+
+// PortfolioOptions configures SelectPortfolio. Zero fields take the
+// documented defaults.
+type PortfolioOptions struct {
+	Method      PortfolioMethod // Search method (default Auto)
+	MaxExact    int             // Auto uses Exact up to this many candidates (default 30, at most MaxExactCandidates)
+	MaxNodes    int             // Exact stops after this many branch-and-bound nodes (default 1,000,000)
+	MaxProjects int             // Most candidates in the portfolio (default no limit)
+}
+
+func (o PortfolioOptions) withDefaults() PortfolioOptions {
+	if o.MaxExact <= 0 {
+		o.MaxExact = 30
+	}
+	o.MaxExact = min(o.MaxExact, MaxExactCandidates)
+	if o.MaxNodes <= 0 {
+		o.MaxNodes = 1_000_000
+	}
+	return o
+}
+
+// Portfolio is a set of candidates that share no pipes and fit the budget.
+type Portfolio struct {
+	Projects []Candidate     // Selected candidates, in input order
+	Cost     float64         // Total cost of the projects
+	Benefit  float64         // Total BRE reduction of the projects
+	Bound    float64         // No portfolio within the budget has a larger benefit
+	Gap      float64         // (Bound-Benefit)/Bound; 0 means the portfolio is optimal
+	Method   PortfolioMethod // Method that was used, Exact or Greedy
+}
+
+// SelectPortfolio picks candidates that share no pipes and cost at most
+// budget in total, and no more than MaxProjects of them, maximising the
+// total benefit. Candidates without a positive benefit or costing more than
+// the budget are never selected.
+//
+// Exact runs a branch-and-bound over the candidates in order of benefit per
+// €, bounded by the fractional knapsack of the remaining compatible
+// candidates and by the largest benefits of as many of them as MaxProjects
+// still allows. It finds the optimal portfolio unless it runs out of
+// MaxNodes, in which case the best portfolio found is returned with its
+// gap. Greedy takes candidates in order of benefit per € and skips those
+// that overlap an earlier pick or no longer fit; its gap is measured
+// against the fractional knapsack of all candidates, which ignores
+// overlaps and MaxProjects, so the true gap is often smaller.
+//
+// A candidate with a non-positive cost, a negative budget or more than
+// MaxExactCandidates candidates for Exact is an error.
+func SelectPortfolio(cands []Candidate, budget float64, opts PortfolioOptions) (*Portfolio, error) {
+	opts = opts.withDefaults()
+	if budget < 0 || math.IsNaN(budget) {
+		return nil, fmt.Errorf("planner: invalid portfolio budget %v", budget)
+	}
+
+	// Usable candidates, by benefit per € (then larger benefit, then cluster
+	// ID, then input order)
+	var items []int
+	for i, c := range cands {
+		if !(c.Cost > 0) {
+			return nil, fmt.Errorf("planner: candidate %d has non-positive cost %v", c.ID, c.Cost)
+		}
+		if c.Benefit > 0 && c.Cost <= budget {
+			items = append(items, i)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := cands[items[i]], cands[items[j]]
+		if ra, rb := a.Benefit/a.Cost, b.Benefit/b.Cost; ra != rb {
+			return ra > rb
+		}
+		if a.Benefit != b.Benefit {
+			return a.Benefit > b.Benefit
+		}
+		return a.ID < b.ID
+	})
+
+	method := opts.Method
+	if method == Auto {
+		method = Greedy
+		if len(items) <= opts.MaxExact {
+			method = Exact
+		}
+	}
+
+	p := &Portfolio{Method: method}
+	var picked []int
+	optimal := false
+	switch method {
+	case Exact:
+		if len(items) > MaxExactCandidates {
+			return nil, errors.New("planner: too many candidates for an exact portfolio")
+		}
+		picked, optimal = exactPortfolio(cands, items, budget, opts.MaxProjects, opts.MaxNodes)
+	case Greedy:
+		picked = greedyPortfolio(cands, items, budget, opts.MaxProjects)
+	default:
+		return nil, fmt.Errorf("planner: unknown portfolio method %v", method)
+	}
+
+	sort.Ints(picked)
+	for _, i := range picked {
+		p.Projects = append(p.Projects, cands[i])
+		p.Cost += cands[i].Cost
+		p.Benefit += cands[i].Benefit
+	}
+	p.Bound = p.Benefit
+	if !optimal {
+		p.Bound = max(p.Benefit, fractionalBound(cands, items, 0, 0, budget))
+	}
+	if p.Bound > 0 {
+		p.Gap = (p.Bound - p.Benefit) / p.Bound
+	}
+	return p, nil
+}
+
+// greedyPortfolio takes items in order while they fit and share no pipes
+// with an earlier pick, up to maxProjects of them if positive, and returns
+// the picked candidate indices.
+func greedyPortfolio(cands []Candidate, items []int, budget float64, maxProjects int) []int {
+	claimed := make(map[graph.ID]bool)
+	var picked []int
+	var cost float64
+	for _, i := range items {
+		if maxProjects > 0 && len(picked) == maxProjects {
+			break
+		}
+		c := cands[i]
+		if cost+c.Cost > budget || overlaps(c.Nodes, claimed) {
+			continue
+		}
+		for _, id := range c.Nodes {
+			claimed[id] = true
+		}
+		cost += c.Cost
+		picked = append(picked, i)
+	}
+	return picked
+}
+
+func overlaps(nodes []graph.ID, claimed map[graph.ID]bool) bool {
+	for _, id := range nodes {
+		if claimed[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// fractionalBound is the best benefit of the items from position k on that
+// are not in blocked, when the last one taken may be taken in part. With
+// items in order of benefit per € this bounds any whole selection of them.
+func fractionalBound(cands []Candidate, items []int, k int, blocked uint64, room float64) float64 {
+	var bound float64
+	for j := k; j < len(items); j++ {
+		if blocked&(1<<j) != 0 {
+			continue
+		}
+		c := cands[items[j]]
+		if c.Cost <= room {
+			room -= c.Cost
+			bound += c.Benefit
+			continue
+		}
+		return bound + c.Benefit*room/c.Cost
+	}
+	return bound
+}
+
+// exactPortfolio runs the branch-and-bound and returns the picked candidate
+// indices and whether the search completed within maxNodes.
+func exactPortfolio(cands []Candidate, items []int, budget float64, maxProjects, maxNodes int) ([]int, bool) {
+	// conflict[k] has bit j set when items k and j share a pipe
+	conflict := make([]uint64, len(items))
+	owners := make(map[graph.ID]uint64)
+	for k, i := range items {
+		for _, id := range cands[i].Nodes {
+			conflict[k] |= owners[id]
+			owners[id] |= 1 << k
+		}
+	}
+	for k := range items {
+		for j := k + 1; j < len(items); j++ {
+			if conflict[j]&(1<<k) != 0 {
+				conflict[k] |= 1 << j
+			}
+		}
+	}
+
+	if maxProjects <= 0 {
+		maxProjects = len(items)
+	}
+	b := &branchAndBound{cands: cands, items: items, conflict: conflict, budget: budget,
+		maxProjects: maxProjects, maxNodes: maxNodes}
+	b.byBenefit = make([]int, len(items))
+	for k := range items {
+		b.byBenefit[k] = k
+	}
+	sort.SliceStable(b.byBenefit, func(i, j int) bool {
+		return cands[items[b.byBenefit[i]]].Benefit > cands[items[b.byBenefit[j]]].Benefit
+	})
+
+	// Start from the greedy portfolio so that weak branches are cut early
+	for _, i := range greedyPortfolio(cands, items, budget, maxProjects) {
+		for k, j := range items {
+			if j == i {
+				b.bestSet |= 1 << k
+				b.best += cands[i].Benefit
+			}
+		}
+	}
+	b.search(0, 0, 0, 0, 0, 0)
+
+	var picked []int
+	for k, i := range items {
+		if b.bestSet&(1<<k) != 0 {
+			picked = append(picked, i)
+		}
+	}
+	return picked, b.nodes <= b.maxNodes
+}
+
+type branchAndBound struct {
+	cands       []Candidate
+	items       []int
+	conflict    []uint64
+	budget      float64
+	maxProjects int
+	maxNodes    int
+	nodes       int
+	byBenefit   []int // item positions by decreasing benefit
+
+	best    float64 // benefit of the best portfolio so far
+	bestSet uint64  // its items
+}
+
+// search decides item k given the items chosen so far, how many there are,
+// the items blocked by them and their total cost and benefit.
+func (b *branchAndBound) search(k int, chosen, blocked uint64, count int, cost, benefit float64) {
+	if benefit > b.best {
+		b.best, b.bestSet = benefit, chosen
+	}
+	if k == len(b.items) || count == b.maxProjects || b.nodes > b.maxNodes {
+		return
+	}
+	b.nodes++
+	room := b.budget - cost
+	if benefit+min(fractionalBound(b.cands, b.items, k, blocked, room), b.countBound(k, blocked, b.maxProjects-count, room)) <= b.best {
+		return
+	}
+
+	c := b.cands[b.items[k]]
+	if blocked&(1<<k) == 0 && c.Cost <= room {
+		b.search(k+1, chosen|1<<k, blocked|b.conflict[k], count+1, cost+c.Cost, benefit+c.Benefit)
+	}
+	b.search(k+1, chosen, blocked, count, cost, benefit)
+}
+
+// countBound is the benefit of the n largest items from position k on that
+// are not blocked and fit in room on their own, which bounds any selection
+// of at most n of them.
+func (b *branchAndBound) countBound(k int, blocked uint64, n int, room float64) float64 {
+	var bound float64
+	for _, j := range b.byBenefit {
+		if n == 0 {
+			break
+		}
+		if c := b.cands[b.items[j]]; j >= k && blocked&(1<<j) == 0 && c.Cost <= room {
+			bound += c.Benefit
+			n--
+		}
+	}
+	return bound
+}
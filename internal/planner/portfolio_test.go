@@ -0,0 +1,219 @@
+package planner
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+func candidate(id int, cost, benefit float64, pipes ...graph.ID) Candidate {
+	return Candidate{Cluster: Cluster{ID: id, Nodes: pipes}, Cost: cost, Benefit: benefit}
+}
+
+func TestSelectPortfolio(t *testing.T) {
+	// 1 has the best benefit per € but overlaps both 2 and 3, which together
+	// fit the budget and remove more risk
+	cands := []Candidate{
+		candidate(1, 10, 30, 1, 2),
+		candidate(2, 6, 17, 1),
+		candidate(3, 6, 17, 2),
+		candidate(4, 5, 0, 3), // no benefit, never selected
+	}
+
+	tests := []struct {
+		name     string
+		budget   float64
+		opts     PortfolioOptions
+		expected []int // selected cluster IDs
+		benefit  float64
+		bound    float64
+		method   PortfolioMethod
+	}{
+		{
+			name:     "exact",
+			budget:   12,
+			expected: []int{2, 3},
+			benefit:  34,
+			bound:    34,
+			method:   Exact,
+		},
+		{
+			// Bound: all of 1 and 2/6 of 2, i.e. 30 + 17/3
+			name:     "greedy",
+			budget:   12,
+			opts:     PortfolioOptions{Method: Greedy},
+			expected: []int{1},
+			benefit:  30,
+			bound:    30 + 17.0/3,
+			method:   Greedy,
+		},
+		{
+			// Only 2 candidates usable, above MaxExact
+			name:     "auto falls back to greedy",
+			budget:   12,
+			opts:     PortfolioOptions{MaxExact: 1},
+			expected: []int{1},
+			benefit:  30,
+			bound:    30 + 17.0/3,
+			method:   Greedy,
+		},
+		{
+			name:     "room for everything that does not overlap",
+			budget:   100,
+			expected: []int{2, 3},
+			benefit:  34,
+			bound:    34,
+			method:   Exact,
+		},
+		{
+			// 2 and 3 together are better, but only one project is allowed
+			name:     "at most one project",
+			budget:   12,
+			opts:     PortfolioOptions{MaxProjects: 1},
+			expected: []int{1},
+			benefit:  30,
+			bound:    30,
+			method:   Exact,
+		},
+		{
+			name:   "budget below every cost",
+			budget: 5,
+			method: Exact,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := SelectPortfolio(cands, tt.budget, tt.opts)
+			if err != nil {
+				t.Fatalf("SelectPortfolio: %v", err)
+			}
+			var ids []int
+			for _, c := range p.Projects {
+				ids = append(ids, c.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("expected projects %v, got %v", tt.expected, ids)
+			}
+			if p.Benefit != tt.benefit || math.Abs(p.Bound-tt.bound) > 1e-9 {
+				t.Errorf("expected benefit %v and bound %v, got %v and %v", tt.benefit, tt.bound, p.Benefit, p.Bound)
+			}
+			if p.Method != tt.method {
+				t.Errorf("expected method %v, got %v", tt.method, p.Method)
+			}
+			if optimal := tt.bound == tt.benefit; optimal != (p.Gap == 0) {
+				t.Errorf("expected optimal %v, got gap %v", optimal, p.Gap)
+			}
+		})
+	}
+}
+
+func TestSelectPortfolioMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for round := 0; round < 50; round++ {
+		// 12 candidates of 1-4 pipes out of 20, so many overlap
+		cands := make([]Candidate, 12)
+		for i := range cands {
+			var pipes []graph.ID
+			for n := 1 + rng.Intn(4); len(pipes) < n; {
+				pipes = append(pipes, graph.ID(rng.Intn(20)))
+			}
+			cands[i] = candidate(i+1, 1+float64(rng.Intn(20)), float64(rng.Intn(50)), pipes...)
+		}
+		budget := float64(10 + rng.Intn(40))
+		maxProjects := rng.Intn(4) // 0 for no limit
+
+		best := 0.0
+		for set := 0; set < 1<<len(cands); set++ {
+			var chosen []Candidate
+			for i := range cands {
+				if set&(1<<i) != 0 {
+					chosen = append(chosen, cands[i])
+				}
+			}
+			if maxProjects > 0 && len(chosen) > maxProjects {
+				continue
+			}
+			if cost, benefit, ok := feasible(chosen); ok && cost <= budget {
+				best = max(best, benefit)
+			}
+		}
+
+		for _, method := range []PortfolioMethod{Exact, Greedy} {
+			p, err := SelectPortfolio(cands, budget, PortfolioOptions{Method: method, MaxProjects: maxProjects})
+			if err != nil {
+				t.Fatalf("SelectPortfolio: %v", err)
+			}
+			if _, _, ok := feasible(p.Projects); !ok || p.Cost > budget || (maxProjects > 0 && len(p.Projects) > maxProjects) {
+				t.Fatalf("round %d, %v: portfolio overlaps or exceeds the budget or project limit: %+v", round, method, p)
+			}
+			if method == Exact && p.Benefit != best {
+				t.Errorf("round %d: expected the optimal benefit %v, got %v", round, best, p.Benefit)
+			}
+			if p.Benefit > best || p.Bound < best {
+				t.Errorf("round %d, %v: expected %v <= optimum %v <= %v", round, method, p.Benefit, best, p.Bound)
+			}
+		}
+	}
+}
+
+// feasible sums the candidates and reports whether they share no pipes.
+func feasible(cands []Candidate) (cost, benefit float64, ok bool) {
+	claimed := make(map[graph.ID]bool)
+	for _, c := range cands {
+		if overlaps(c.Nodes, claimed) {
+			return 0, 0, false
+		}
+		for _, id := range c.Nodes {
+			claimed[id] = true
+		}
+		cost += c.Cost
+		benefit += c.Benefit
+	}
+	return cost, benefit, true
+}
+
+func TestSelectPortfolioNodeLimit(t *testing.T) {
+	cands := []Candidate{
+		candidate(1, 10, 30, 1, 2),
+		candidate(2, 6, 17, 1),
+		candidate(3, 6, 17, 2),
+	}
+	// One node is not enough to improve on the greedy start
+	p, err := SelectPortfolio(cands, 12, PortfolioOptions{Method: Exact, MaxNodes: 1})
+	if err != nil {
+		t.Fatalf("SelectPortfolio: %v", err)
+	}
+	if p.Benefit != 30 || p.Gap == 0 {
+		t.Errorf("expected the greedy benefit 30 with a gap, got %v with gap %v", p.Benefit, p.Gap)
+	}
+}
+
+func TestSelectPortfolioErrors(t *testing.T) {
+	many := make([]Candidate, MaxExactCandidates+1)
+	for i := range many {
+		many[i] = candidate(i+1, 1, 1, graph.ID(i))
+	}
+
+	tests := []struct {
+		name   string
+		cands  []Candidate
+		budget float64
+		opts   PortfolioOptions
+	}{
+		{name: "zero cost", cands: []Candidate{candidate(1, 0, 5, 1)}, budget: 10},
+		{name: "negative budget", cands: []Candidate{candidate(1, 1, 5, 1)}, budget: -1},
+		{name: "too many for exact", cands: many, budget: 10, opts: PortfolioOptions{Method: Exact}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SelectPortfolio(tt.cands, tt.budget, tt.opts); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
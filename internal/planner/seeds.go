@@ -0,0 +1,165 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+	"github.com/thanos-fil/planner-demo-go/internal/neighbourhood"
+)
+
+// SeedStrategy picks the pipes clusters are grown from. Seeds returns them
+// in the order they should be tried, best first.
+type SeedStrategy interface {
+	Name() string
+	Seeds(net *graph.Network, cfg UserCfg) ([]graph.ID, error)
+}
+
+// Selection limits how many of the pipes ranked by a built-in strategy are
+// returned as seeds.
+type Selection struct {
+	Quantile float64 // Keep this top fraction of the ranked pipes, rounded up (<= 0 or >= 1 keeps all)
+	Cap      int     // Keep at most this many seeds (<= 0 means no cap)
+}
+
+// apply cuts ranked down to the selection.
+func (s Selection) apply(ranked []graph.ID) []graph.ID {
+	n := len(ranked)
+	if s.Quantile > 0 && s.Quantile < 1 {
+		n = int(math.Ceil(float64(n) * s.Quantile))
+	}
+	if s.Cap > 0 {
+		n = min(n, s.Cap)
+	}
+	return ranked[:n]
+}
+
+// HighLoF ranks pipes by score, highest first. The zero value is the
+// default strategy of CreateCandidateClusters.
+type HighLoF struct {
+	Selection
+}
+
+func (HighLoF) Name() string { return "high-lof" }
+
+func (s HighLoF) Seeds(net *graph.Network, _ UserCfg) ([]graph.ID, error) {
+	return s.apply(rankPipes(net, func(n *graph.Node) float64 { return n.Score })), nil
+}
+
+// LoFPerLength ranks pipes by score per metre, which favours short risky
+// pipes that leave room in a cluster for their neighbours.
+type LoFPerLength struct {
+	Selection
+}
+
+func (LoFPerLength) Name() string { return "lof-per-length" }
+
+func (s LoFPerLength) Seeds(net *graph.Network, _ UserCfg) ([]graph.ID, error) {
+	return s.apply(rankPipes(net, func(n *graph.Node) float64 { return n.Score / n.Length })), nil
+}
+
+// RiskDensity ranks pipes by the risk per kilometre of their neighbourhood,
+// so seeds sit in areas with many risky pipes rather than on one.
+type RiskDensity struct {
+	Selection
+	Radius   float64               // Neighbourhood budget (0 means MaxLength/2)
+	Progress func(done, total int) // Optional, called as in neighbourhood.BatchOptions
+	Context  context.Context       // Optional, cancelling it stops the neighbourhood searches
+}
+
+func (RiskDensity) Name() string { return "risk-density" }
+
+func (s RiskDensity) Seeds(net *graph.Network, cfg UserCfg) ([]graph.ID, error) {
+	radius := s.Radius
+	if radius <= 0 {
+		radius = cfg.MaxLength / 2
+	}
+	ctx := s.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	summaries, err := neighbourhood.Batch(ctx, net, nil, radius, neighbourhood.BatchOptions{Progress: s.Progress})
+	if err != nil {
+		return nil, err
+	}
+	density := make(map[graph.ID]float64, len(summaries))
+	for _, sum := range summaries {
+		if sum.TotalLength > 0 {
+			density[sum.Root] = sum.TotalRisk / (sum.TotalLength / 1000)
+		}
+	}
+	return s.apply(rankPipes(net, func(n *graph.Node) float64 { return density[n.ID] })), nil
+}
+
+// rankPipes returns the pipes with a positive score and a positive rank
+// value, highest value first and lowest ID first among equal values.
+func rankPipes(net *graph.Network, value func(*graph.Node) float64) []graph.ID {
+	values := make(map[graph.ID]float64)
+	var ids []graph.ID
+	for id, node := range net.Nodes {
+		if v := value(node); node.Score > 0 && v > 0 {
+			values[id] = v
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if values[ids[i]] != values[ids[j]] {
+			return values[ids[i]] > values[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]SeedStrategy{}
+)
+
+func init() {
+	for _, s := range []SeedStrategy{HighLoF{}, LoFPerLength{}, RiskDensity{}} {
+		if err := RegisterSeedStrategy(s); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterSeedStrategy makes s available by name through
+// LookupSeedStrategy, e.g. for selecting it from a command line flag. The
+// built-in strategies are registered with their default Selection.
+func RegisterSeedStrategy(s SeedStrategy) error {
+	if s == nil || s.Name() == "" {
+		return errors.New("planner: seed strategy without a name")
+	}
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	if _, ok := strategies[s.Name()]; ok {
+		return fmt.Errorf("planner: seed strategy %q already registered", s.Name())
+	}
+	strategies[s.Name()] = s
+	return nil
+}
+
+// LookupSeedStrategy returns the strategy registered under name.
+func LookupSeedStrategy(name string) (SeedStrategy, bool) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	s, ok := strategies[name]
+	return s, ok
+}
+
+// SeedStrategies returns the names of all registered strategies, sorted.
+func SeedStrategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,165 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/thanos-fil/planner-demo-go/internal/graph"
+)
+
+// fixedSeeds is a custom strategy that always returns the same pipes
+type fixedSeeds []graph.ID
+
+func (fixedSeeds) Name() string { return "fixed" }
+
+func (s fixedSeeds) Seeds(*graph.Network, UserCfg) ([]graph.ID, error) { return s, nil }
+
+func TestSeedStrategies(t *testing.T) {
+	net := MockExampleNetwork()
+	cfg := UserCfg{MaxLength: 3.0}
+
+	tests := []struct {
+		name     string
+		strategy SeedStrategy
+		expected []graph.ID
+	}{
+		{
+			// 11 and 12 tie on 0.3, lowest ID first
+			name:     "high lof",
+			strategy: HighLoF{},
+			expected: []graph.ID{16, 9, 1, 5, 11, 12},
+		},
+		{
+			// Half of the 6 risky pipes
+			name:     "quantile",
+			strategy: HighLoF{Selection: Selection{Quantile: 0.5}},
+			expected: []graph.ID{16, 9, 1},
+		},
+		{
+			// 0.1 of 6 is 0.6 pipes, rounded up to one
+			name:     "small quantile rounds up",
+			strategy: HighLoF{Selection: Selection{Quantile: 0.1}},
+			expected: []graph.ID{16},
+		},
+		{
+			name:     "cap",
+			strategy: HighLoF{Selection: Selection{Quantile: 0.5, Cap: 2}},
+			expected: []graph.ID{16, 9},
+		},
+		{
+			// 12 is 1.5 long, so 0.3/1.5 = 0.2 puts it behind 11
+			name:     "lof per length",
+			strategy: LoFPerLength{},
+			expected: []graph.ID{16, 9, 1, 5, 11, 12},
+		},
+		{
+			// Within 3.0: 1, 5 and 9 all reach the whole chain (1.5 over 3m),
+			// 16 reaches 12 (1.1 over 2.5m), 12 reaches both ends (1.4 over
+			// 3.5m) and 11 reaches 12 (0.6 over 2.5m)
+			name:     "risk density",
+			strategy: RiskDensity{Radius: 3.0},
+			expected: []graph.ID{1, 5, 9, 16, 12, 11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seeds, err := tt.strategy.Seeds(net, cfg)
+			if err != nil {
+				t.Fatalf("Seeds: %v", err)
+			}
+			if !reflect.DeepEqual(seeds, tt.expected) {
+				t.Errorf("expected seeds %v, got %v", tt.expected, seeds)
+			}
+		})
+	}
+}
+
+func TestRiskDensityCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := RiskDensity{Radius: 3.0, Context: ctx}.Seeds(MockExampleNetwork(), UserCfg{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCreateCandidateClustersSeedStrategy(t *testing.T) {
+	net := MockExampleNetwork()
+
+	tests := []struct {
+		name     string
+		cfg      UserCfg
+		expected [][]graph.ID
+	}{
+		{
+			name:     "capped strategy",
+			cfg:      UserCfg{MaxLength: 3.0, SeedStrategy: HighLoF{Selection: Selection{Cap: 1}}},
+			expected: [][]graph.ID{{12, 16}},
+		},
+		{
+			// 11 seeds first and takes 12 before 16 can
+			name:     "custom strategy",
+			cfg:      UserCfg{MaxLength: 3.0, SeedStrategy: fixedSeeds{11, 16}},
+			expected: [][]graph.ID{{16}, {11, 12}},
+		},
+		{
+			// Must-include pipes come first, excluded pipes are dropped
+			name: "constraints apply",
+			cfg: UserCfg{MaxLength: 3.0, SeedStrategy: fixedSeeds{11, 16},
+				Include: []graph.ID{9}, Exclude: []graph.ID{11}},
+			expected: [][]graph.ID{{1, 5, 9}, {12, 16}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters, err := CreateCandidateClusters(net, tt.cfg)
+			if err != nil {
+				t.Fatalf("CreateCandidateClusters: %v", err)
+			}
+			var got [][]graph.ID
+			for _, c := range clusters {
+				got = append(got, c.Nodes)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected clusters %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := CreateCandidateClusters(net, UserCfg{MaxLength: 3.0, SeedStrategy: fixedSeeds{99}}); err == nil {
+		t.Errorf("expected an error for a seed that is not in the network")
+	}
+}
+
+func TestRegisterSeedStrategy(t *testing.T) {
+	for _, name := range []string{"high-lof", "lof-per-length", "risk-density"} {
+		if _, ok := LookupSeedStrategy(name); !ok {
+			t.Errorf("expected built-in strategy %q to be registered", name)
+		}
+	}
+
+	if err := RegisterSeedStrategy(fixedSeeds{11}); err != nil {
+		t.Fatalf("RegisterSeedStrategy: %v", err)
+	}
+	t.Cleanup(func() {
+		strategiesMu.Lock()
+		delete(strategies, "fixed")
+		strategiesMu.Unlock()
+	})
+	s, ok := LookupSeedStrategy("fixed")
+	if !ok || !reflect.DeepEqual(s, fixedSeeds{11}) {
+		t.Errorf("expected the registered strategy back, got %v", s)
+	}
+	if err := RegisterSeedStrategy(fixedSeeds{12}); err == nil {
+		t.Errorf("expected an error registering the same name twice")
+	}
+
+	expected := []string{"fixed", "high-lof", "lof-per-length", "risk-density"}
+	if names := SeedStrategies(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected strategies %v, got %v", expected, names)
+	}
+}